@@ -0,0 +1,80 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fr3dr/termtyper/config"
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/words"
+)
+
+// focusCount is how many of the weakest characters adaptive mode practices
+const focusCount = 5
+
+// minSamples is how often a character has to be typed before its accuracy is trusted
+const minSamples = 10
+
+// practiceSource returns the words source of symbols, adaptive or drill mode and what it practices,
+// it is created again after every result as the weakest characters change
+func practiceSource(cfg config.Config, stats *db.DB, wordList []string) (words.Source, string, error) {
+	switch {
+	case cfg.Symbols:
+		return symbolsSource(stats, cfg.SymbolSet, cfg.AdaptiveStrength)
+	case cfg.Drill:
+		return drillSource(stats, wordList, cfg.AdaptiveStrength)
+	case cfg.Adaptive:
+		return adaptiveSource(stats, wordList, cfg.AdaptiveStrength)
+	default:
+		return words.NewList(wordList), "", nil
+	}
+}
+
+// adaptiveSource picks words containing the weakest characters more often, the higher strength the more often
+func adaptiveSource(stats *db.DB, wordList []string, strength float64) (words.Source, string, error) {
+	charStats, err := stats.GetCharStats()
+	if err != nil {
+		return nil, "", err
+	}
+	focus := weakChars(charStats, wordList)
+	if len(focus) == 0 {
+		return words.NewList(wordList), "", nil
+	}
+
+	return words.NewWeighted(wordList, focus, strength), focusInfo(focus), nil
+}
+
+// focusInfo describes the characters that are practiced
+func focusInfo(focus []string) string {
+	labels := make([]string, len(focus))
+	for i, f := range focus {
+		labels[i] = charLabel([]rune(f)[0])
+	}
+	return "focus: " + strings.Join(labels, " ")
+}
+
+// weakChars returns the least accurate characters that appear in the word list
+func weakChars(charStats []*db.CharStat, wordList []string) []string {
+	inList := map[rune]bool{}
+	for _, word := range wordList {
+		for _, r := range word {
+			inList[r] = true
+		}
+	}
+
+	var weak []*db.CharStat
+	for _, v := range charStats {
+		if inList[v.Char] && v.Incorrect > 0 && v.Correct+v.Incorrect >= minSamples {
+			weak = append(weak, v)
+		}
+	}
+	sort.SliceStable(weak, func(i, j int) bool {
+		return weak[i].Accuracy < weak[j].Accuracy
+	})
+
+	var focus []string
+	for _, v := range weak[:min(focusCount, len(weak))] {
+		focus = append(focus, string(v.Char))
+	}
+	return focus
+}
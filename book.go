@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/target"
+)
+
+// book is a text that is typed in order, a page per test.
+// Where typing stopped in a file is kept in the stats database.
+type book struct {
+	// path is empty for text piped to stdin
+	path     string
+	text     []rune
+	position int
+}
+
+// loadBook reads a text file and the position it was last typed to.
+// All whitespace is typed as single spaces.
+func loadBook(path string, stats *db.DB) (*book, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := plainText(string(data))
+	if len(text) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	progress, err := stats.GetProgress(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &book{path: path, text: text, position: progress.Position}
+	// start over once the end is reached or if the file got shorter
+	if b.position >= len(b.text) {
+		b.position = 0
+	}
+	return b, nil
+}
+
+// readBook reads a book from text piped to stdin, its progress is not saved
+func readBook(r io.Reader) (*book, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := plainText(string(data))
+	if len(text) == 0 {
+		return nil, errors.New("no text was piped to stdin")
+	}
+	return &book{text: text}, nil
+}
+
+// plainText removes the formatting of terminal output, like colors and the overstrikes of man pages,
+// and joins all whitespace into single spaces
+func plainText(data string) []rune {
+	var text []rune
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRuneInString(data[i:])
+		i += size
+		switch {
+		case r == '\033':
+			// skip a control sequence up to its final byte
+			if i < len(data) && data[i] == '[' {
+				i++
+				for i < len(data) && (data[i] < 0x40 || data[i] > 0x7e) {
+					i++
+				}
+			}
+			i++
+		case r == '\b':
+			// an overstrike replaces the character before it
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case unicode.IsSpace(r):
+			text = append(text, ' ')
+		case unicode.IsGraphic(r):
+			text = append(text, r)
+		}
+	}
+	return []rune(strings.Join(strings.Fields(string(text)), " "))
+}
+
+// name is shown after the result of a page
+func (b *book) name() string {
+	if b.path == "" {
+		return "stdin"
+	}
+	return filepath.Base(b.path)
+}
+
+// page returns the text from the current position that fills at most rows lines of width columns,
+// and the position after it
+func (b *book) page(width int, rows int) (string, int) {
+	rows = max(rows, 1)
+	// more than enough of the text for rows lines, ending after a word
+	end := min(b.position+(rows+1)*width, len(b.text))
+	for end < len(b.text) && b.text[end-1] != ' ' {
+		end++
+	}
+	// the page is wrapped the same way the session wraps it
+	text := target.New([]string{string(b.text[b.position:end])})
+	text.Reflow(width)
+	if text.Lines() > rows {
+		end = b.position + text.LineStart(rows)
+	}
+	return strings.TrimRight(string(b.text[b.position:end]), " "), end
+}
+
+// percent returns how much of the book is typed at position
+func (b *book) percent(position int) float64 {
+	return float64(position) / float64(len(b.text)) * 100
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"strings"
+)
+
+// tabWidth is how many spaces a tab is expanded to when indentation is skipped
+const tabWidth = 4
+
+// loadCode reads a source file into lines that can be typed.
+// Trailing whitespace is removed and tabs are expanded to spaces unless they have to be typed.
+func loadCode(path string, typeIndent bool) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var code []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if !typeIndent {
+			line = strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+		}
+		code = append(code, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(strings.Join(code, "")) == "" {
+		return nil, errors.New("code file is empty")
+	}
+	return code, nil
+}
+
+// codeBlock picks a random block of at most maxLines lines that does not start or end with an empty line.
+// Blocks start early enough to be full length so the end of the file is not typed on its own.
+// Every line but the last ends with a newline that has to be typed.
+func codeBlock(code []string, maxLines int) []string {
+	maxLines = max(maxLines, 1)
+	var starts []int
+	for i, line := range code {
+		if strings.TrimSpace(line) != "" && (i+maxLines <= len(code) || len(starts) == 0) {
+			starts = append(starts, i)
+		}
+	}
+	start := starts[rand.IntN(len(starts))]
+	end := min(start+maxLines, len(code))
+	for strings.TrimSpace(code[end-1]) == "" {
+		end--
+	}
+
+	lines := make([]string, 0, end-start)
+	for _, line := range code[start : end-1] {
+		lines = append(lines, line+"\n")
+	}
+	return append(lines, code[end-1])
+}
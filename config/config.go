@@ -4,22 +4,52 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"os"
 )
 
 type Config struct {
 	// flag and file
-	WordCount       int    `json:"word_count"`
-	WordListAmmount int    `json:"word_list_ammount"`
-	MaxLineLength   int    `json:"max_line_length"`
-	TimedMode       int    `json:"timed_mode"`
-	NoBackspace     bool   `json:"no_backspace"`
-	CorrectOnly     bool   `json:"correct_only"`
-	CursorShape     string `json:"cursor_shape"`
-	WordListFile    string `json:"word_list_file"`
+	WordCount        int     `json:"word_count"`
+	WordListAmmount  int     `json:"word_list_ammount"`
+	MaxLineLength    int     `json:"max_line_length"`
+	TimedMode        int     `json:"timed_mode"`
+	NoBackspace      bool    `json:"no_backspace"`
+	CorrectOnly      bool    `json:"correct_only"`
+	CursorShape      string  `json:"cursor_shape"`
+	WordListFile     string  `json:"word_list_file"`
+	Quote            string  `json:"quote"`
+	CodeFile         string  `json:"code_file"`
+	TypeIndent       bool    `json:"type_indent"`
+	Punctuation      bool    `json:"punctuation"`
+	Capitals         bool    `json:"capitals"`
+	Numbers          bool    `json:"numbers"`
+	Zen              bool    `json:"zen"`
+	BookFile         string  `json:"book_file"`
+	Adaptive         bool    `json:"adaptive"`
+	AdaptiveStrength float64 `json:"adaptive_strength"`
+	Drill            bool    `json:"drill"`
+	Symbols          bool    `json:"symbols"`
+	SymbolSet        string  `json:"symbol_set"`
+	Ghost            bool    `json:"ghost"`
+	Pace             float64 `json:"pace"`
+	SuddenDeath      bool    `json:"sudden_death"`
+	MinAccuracy      float64 `json:"min_accuracy"`
+	MinWPM           float64 `json:"min_wpm"`
+	Blind            bool    `json:"blind"`
+	Memory           int     `json:"memory"`
+	Lesson           int     `json:"lesson"`
+	Intervals        int     `json:"intervals"`
+	Rest             int     `json:"rest"`
 
 	// flag only
 	ShowStats bool
+	// ShowFailed includes failed tests in the averages of the stats
+	ShowFailed bool
+	// Stdin is set by passing - to read the text from stdin
+	Stdin bool
+	// Command is the first argument, like lessons
+	Command string
 }
 
 func GetConfig(defaultConfig Config) (Config, error) {
@@ -47,16 +77,46 @@ func GetConfig(defaultConfig Config) (Config, error) {
 	}
 
 	// get configs from flags
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [- | lessons]\n  -\ttype the text piped to stdin\n  lessons\tlist the lessons and how far you got, start one with -lesson\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.IntVar(&config.WordCount, "w", config.WordCount, "number of words")
 	flag.IntVar(&config.WordListAmmount, "n", config.WordListAmmount, "ammount of words to use from word list. max: 1000")
-	flag.IntVar(&config.MaxLineLength, "l", config.MaxLineLength, "max length each line can be")
+	flag.IntVar(&config.MaxLineLength, "l", config.MaxLineLength, "max length each line can be, 0 uses the terminal width")
 	flag.IntVar(&config.TimedMode, "t", config.TimedMode, "timed mode ")
 	flag.BoolVar(&config.NoBackspace, "b", config.NoBackspace, "no backspace mode")
 	flag.BoolVar(&config.CorrectOnly, "o", config.CorrectOnly, "only continue once the correct character is typed")
 	flag.BoolVar(&config.ShowStats, "s", config.ShowStats, "show stats")
 	flag.StringVar(&config.CursorShape, "c", config.CursorShape, "cursor shape 'bar' 'block' 'underline' leave blank to use default terminal cursor")
 	flag.StringVar(&config.WordListFile, "f", config.WordListFile, "path to word list file")
+	flag.BoolVar(&config.Punctuation, "p", config.Punctuation, "add punctuation to words")
+	flag.BoolVar(&config.Capitals, "caps", config.Capitals, "capitalise the start of sentences and random words")
+	flag.BoolVar(&config.Numbers, "nums", config.Numbers, "mix numbers in with words")
+	flag.BoolVar(&config.Adaptive, "a", config.Adaptive, "adaptive mode, pick words with the least accurate characters more often")
+	flag.Float64Var(&config.AdaptiveStrength, "strength", config.AdaptiveStrength, "how strongly adaptive, drill and symbols mode prefer words with what they practice")
+	flag.BoolVar(&config.Drill, "drill", config.Drill, "drill mode, pick words containing the slowest bigrams and trigrams")
+	flag.BoolVar(&config.Symbols, "symbols", config.Symbols, "symbols mode, type numbers and symbols instead of words, the least accurate more often")
+	flag.StringVar(&config.SymbolSet, "symbol-set", config.SymbolSet, "digits and symbols used in symbols mode")
+	flag.BoolVar(&config.SuddenDeath, "sudden", config.SuddenDeath, "fail the test on the first mistake")
+	flag.Float64Var(&config.MinAccuracy, "min-acc", config.MinAccuracy, "fail the test when accuracy drops below this percentage")
+	flag.Float64Var(&config.MinWPM, "min-wpm", config.MinWPM, "fail the test when wpm drops below this after the first 5 seconds")
+	flag.BoolVar(&config.ShowFailed, "failed", config.ShowFailed, "include failed tests in the averages shown with -s")
+	flag.BoolVar(&config.Blind, "blind", config.Blind, "blind mode, mistakes are only shown once the test is finished")
+	flag.IntVar(&config.Memory, "memory", config.Memory, "memory mode, seconds the text is shown before it has to be typed from memory")
+	flag.IntVar(&config.Lesson, "lesson", config.Lesson, "lesson mode, the lesson to practice, it has to be unlocked by passing the one before")
+	flag.IntVar(&config.Intervals, "intervals", config.Intervals, "interval mode, number of sprints as long as -t or 15 seconds")
+	flag.IntVar(&config.Rest, "rest", config.Rest, "seconds of rest between sprints in interval mode")
+	flag.BoolVar(&config.Ghost, "ghost", config.Ghost, "race a ghost replaying your best run of the same text")
+	flag.Float64Var(&config.Pace, "pace", config.Pace, "race a pacer caret at this wpm, used by -ghost when a text was not typed before")
+	flag.BoolVar(&config.Zen, "zen", config.Zen, "zen mode, type freely without a text and finish with ctrl-d")
+	flag.StringVar(&config.BookFile, "book", config.BookFile, "book mode, path to a text file to type in order, resuming where the last run stopped")
+	flag.StringVar(&config.CodeFile, "code", config.CodeFile, "code mode, path to a source file to type with its own line breaks")
+	flag.BoolVar(&config.TypeIndent, "indent", config.TypeIndent, "type indentation in code mode instead of skipping it, tab types a tab and ctrl-r restarts")
+	flag.StringVar(&config.Quote, "q", config.Quote, "quote mode, a quote length 'short' 'medium' 'long' 'thicc' 'all' or a quote id")
 	flag.Parse()
+	config.Command = flag.Arg(0)
+	config.Stdin = config.Command == "-"
 
 	// word count mode has priority over timed mode
 	// this fixes -w not working when timed_mode is set in the config file
@@ -2,19 +2,63 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"unicode/utf8"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Result struct {
-	WPM       float64
-	Accuracy  float64
-	Correct   int
-	Total     int
-	Mistakes  int
-	TimeTaken float64
+	WPM        float64
+	Accuracy   float64
+	Correct    int
+	Total      int
+	Mistakes   int
+	TimeTaken  float64
+	Pauses     int
+	PausedTime float64
+	Mode       string
+	// QuoteID is 0 if the text was not a quote
+	QuoteID int
+	// Text identifies the text that was typed and Times has the typing time in seconds
+	// at which each of its characters was typed, they are kept to replay the run as a ghost
+	Text  string
+	Times []float64
+	// FailReason is set if a fail condition ended the test
+	FailReason string
+	// IntervalID is the set of sprints the result is part of, 0 if it is not a sprint
+	IntervalID int
+}
+
+// QuoteStat is the best result for a quote
+type QuoteStat struct {
+	QuoteID  int
+	Runs     int
+	BestTime float64
+	BestWPM  float64
+}
+
+// Progress is how far a text file has been typed in book mode
+type Progress struct {
+	File     string
+	Position int
+	Length   int
+	// Correct and Time add up every test of the file
+	Correct int
+	Time    float64
+}
+
+// LessonProgress is the best result of a lesson and whether it was passed
+type LessonProgress struct {
+	LessonID     int
+	Runs         int
+	BestWPM      float64
+	BestAccuracy float64
+	Passed       bool
 }
 
 type CharStat struct {
@@ -24,6 +68,43 @@ type CharStat struct {
 	Accuracy  float64
 }
 
+// NGramStat is how fast and accurately a bigram or trigram is typed.
+// Count and Time only include transitions that were typed correctly.
+type NGramStat struct {
+	NGram  string
+	Count  int
+	Errors int
+	Time   float64
+}
+
+// Latency returns the average time between the first and the last character in seconds
+func (n NGramStat) Latency() float64 {
+	return n.Time / float64(n.Count)
+}
+
+// ErrorRate returns how often the last character was mistyped in percent
+func (n NGramStat) ErrorRate() float64 {
+	return float64(n.Errors) / float64(n.Count+n.Errors) * 100
+}
+
+// DB is an open stats database
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens the stats database, creating it if needed
+func Open(dbFile string) (*DB, error) {
+	db, err := getDB(dbFile)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
 func getDB(dbFile string) (*sql.DB, error) {
 	err := os.MkdirAll(filepath.Dir(dbFile), 0755)
 	if err != nil {
@@ -50,6 +131,14 @@ func getDB(dbFile string) (*sql.DB, error) {
 		return nil, err
 	}
 
+	// add columns that are missing in databases created by older versions
+	for _, c := range statsColumns {
+		err = addColumn(db, "stats", c.name, c.definition)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	query = `CREATE TABLE IF NOT EXISTS chars (
 		char INT PRIMARY KEY,
 		correct REAL,
@@ -61,67 +150,331 @@ func getDB(dbFile string) (*sql.DB, error) {
 		return nil, err
 	}
 
+	for _, table := range nGramTables {
+		query = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			ngram TEXT PRIMARY KEY,
+			count INTEGER NOT NULL,
+			errors INTEGER NOT NULL,
+			time REAL NOT NULL
+		)`, table)
+		_, err = db.Exec(query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `CREATE TABLE IF NOT EXISTS keystrokes (
+		stats_id INTEGER PRIMARY KEY,
+		text TEXT NOT NULL,
+		times TEXT NOT NULL
+	)`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS keystrokes_text ON keystrokes(text)`)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `CREATE TABLE IF NOT EXISTS intervals (
+		id INTEGER NOT NULL PRIMARY KEY,
+		sprints INTEGER NOT NULL,
+		sprint REAL NOT NULL,
+		rest REAL NOT NULL,
+		created DEFAULT CURRENT_TIMESTAMP
+	)`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `CREATE TABLE IF NOT EXISTS lessons (
+		id INTEGER PRIMARY KEY,
+		runs INTEGER NOT NULL,
+		best_wpm REAL NOT NULL,
+		best_accuracy REAL NOT NULL,
+		passed INTEGER NOT NULL
+	)`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `CREATE TABLE IF NOT EXISTS progress (
+		file TEXT PRIMARY KEY,
+		position INTEGER NOT NULL,
+		length INTEGER NOT NULL,
+		correct INTEGER NOT NULL,
+		time REAL NOT NULL
+	)`
+	_, err = db.Exec(query)
+	if err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
 
-func GetAll(dbFile string) ([]*Result, []*CharStat, error) {
-	db, err := getDB(dbFile)
+// nGramTables are the tables of n-gram stats by the length of the n-gram
+var nGramTables = map[int]string{
+	2: "bigrams",
+	3: "trigrams",
+}
+
+type column struct {
+	name       string
+	definition string
+}
+
+// statsColumns are the columns added to the stats table after it was first created
+var statsColumns = []column{
+	{"pauses", "INTEGER NOT NULL DEFAULT 0"},
+	{"paused", "REAL NOT NULL DEFAULT 0"},
+	{"mode", "TEXT NOT NULL DEFAULT 'words'"},
+	{"quote_id", "INTEGER"},
+	{"failed", "INTEGER NOT NULL DEFAULT 0"},
+	{"fail_reason", "TEXT"},
+	{"interval_id", "INTEGER"},
+}
+
+// addColumn adds a column to a table if the table does not have it yet
+func addColumn(db *sql.DB, table string, name string, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info($1)`, table)
 	if err != nil {
-		return nil, nil, err
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var existing string
+		err := rows.Scan(&existing)
+		if err != nil {
+			return err
+		}
+		if existing == name {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	query := `SELECT wpm, accuracy, correct, total, mistakes, time FROM stats`
-	rows, err := db.Query(query)
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, definition))
+	return err
+}
+
+func (d *DB) GetAll() ([]*Result, []*CharStat, error) {
+	query := `SELECT wpm, accuracy, correct, total, mistakes, time, pauses, paused, mode, IFNULL(quote_id, 0), IFNULL(fail_reason, '') FROM stats`
+	rows, err := d.db.Query(query)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer rows.Close()
 	var results []*Result
 	for rows.Next() {
 		var result Result
-		err := rows.Scan(&result.WPM, &result.Accuracy, &result.Correct, &result.Total, &result.Mistakes, &result.TimeTaken)
+		err := rows.Scan(&result.WPM, &result.Accuracy, &result.Correct, &result.Total, &result.Mistakes, &result.TimeTaken, &result.Pauses, &result.PausedTime, &result.Mode, &result.QuoteID, &result.FailReason)
 		if err != nil {
 			return nil, nil, err
 		}
 		results = append(results, &result)
 	}
 
-	query = `SELECT char, correct, incorrect, accuracy FROM chars ORDER BY accuracy DESC`
-	rows, err = db.Query(query)
+	charStats, err := d.GetCharStats()
 	if err != nil {
 		return nil, nil, err
 	}
+
+	return results, charStats, nil
+}
+
+// GetCharStats returns the stats of every character that has been typed, most accurate first
+func (d *DB) GetCharStats() ([]*CharStat, error) {
+	query := `SELECT char, correct, incorrect, accuracy FROM chars ORDER BY accuracy DESC`
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 	var charStats []*CharStat
 	for rows.Next() {
 		var charStat CharStat
 		err := rows.Scan(&charStat.Char, &charStat.Correct, &charStat.Incorrect, &charStat.Accuracy)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		charStats = append(charStats, &charStat)
 	}
+	return charStats, rows.Err()
+}
 
-	return results, charStats, nil
+// GetQuoteStats returns the best time and wpm of every quote that has been typed
+func (d *DB) GetQuoteStats() ([]*QuoteStat, error) {
+	query := `SELECT quote_id, COUNT(*), MIN(time), MAX(wpm) FROM stats WHERE quote_id IS NOT NULL AND failed = 0 GROUP BY quote_id ORDER BY quote_id`
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var quoteStats []*QuoteStat
+	for rows.Next() {
+		var quoteStat QuoteStat
+		err := rows.Scan(&quoteStat.QuoteID, &quoteStat.Runs, &quoteStat.BestTime, &quoteStat.BestWPM)
+		if err != nil {
+			return nil, err
+		}
+		quoteStats = append(quoteStats, &quoteStat)
+	}
+	return quoteStats, rows.Err()
 }
 
-func Save(result Result, charStats map[rune]CharStat, dbFile string) error {
-	db, err := getDB(dbFile)
+// GetNGramStats returns the stats of every n-gram of length n that has been typed
+func (d *DB) GetNGramStats(n int) ([]*NGramStat, error) {
+	query := fmt.Sprintf(`SELECT ngram, count, errors, time FROM %s`, nGramTables[n])
+	rows, err := d.db.Query(query)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+	var nGramStats []*NGramStat
+	for rows.Next() {
+		var nGramStat NGramStat
+		err := rows.Scan(&nGramStat.NGram, &nGramStat.Count, &nGramStat.Errors, &nGramStat.Time)
+		if err != nil {
+			return nil, err
+		}
+		nGramStats = append(nGramStats, &nGramStat)
+	}
+	return nGramStats, rows.Err()
+}
+
+// GetBestTimes returns the keystroke times of the fastest finished run of a text,
+// nil if the text was never typed
+func (d *DB) GetBestTimes(text string) ([]float64, error) {
+	query := `SELECT k.times FROM keystrokes k JOIN stats s ON s.id = k.stats_id WHERE k.text = $1 AND s.failed = 0 ORDER BY s.time LIMIT 1`
+	var data []byte
+	err := d.db.QueryRow(query, text).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var times []float64
+	err = json.Unmarshal(data, &times)
+	return times, err
+}
+
+// StartInterval adds a set of sprints that results are grouped into and returns its id
+func (d *DB) StartInterval(sprints int, sprint float64, rest float64) (int, error) {
+	query := `INSERT INTO intervals(sprints, sprint, rest) VALUES($1, $2, $3)`
+	res, err := d.db.Exec(query, sprints, sprint, rest)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// GetLessonProgress returns the progress of every lesson that has been typed by lesson id
+func (d *DB) GetLessonProgress() (map[int]LessonProgress, error) {
+	query := `SELECT id, runs, best_wpm, best_accuracy, passed FROM lessons`
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	progress := map[int]LessonProgress{}
+	for rows.Next() {
+		var p LessonProgress
+		err := rows.Scan(&p.LessonID, &p.Runs, &p.BestWPM, &p.BestAccuracy, &p.Passed)
+		if err != nil {
+			return nil, err
+		}
+		progress[p.LessonID] = p
+	}
+	return progress, rows.Err()
+}
+
+// SaveLesson adds a result to the progress of a lesson, a lesson stays passed once it was passed
+func (d *DB) SaveLesson(id int, result Result, passed bool) error {
+	query := `INSERT INTO lessons(id, runs, best_wpm, best_accuracy, passed) VALUES($1, 1, $2, $3, $4)
+		ON CONFLICT(id) DO UPDATE SET runs=runs+1, best_wpm=MAX(best_wpm, $2), best_accuracy=MAX(best_accuracy, $3), passed=MAX(passed, $4)`
+	_, err := d.db.Exec(query, id, result.WPM, result.Accuracy, passed)
+	return err
+}
+
+// GetProgress returns how far a file has been typed, the position is 0 if it was never typed
+func (d *DB) GetProgress(file string) (Progress, error) {
+	progress := Progress{File: file}
+	query := `SELECT position, length, correct, time FROM progress WHERE file = $1`
+	err := d.db.QueryRow(query, file).Scan(&progress.Position, &progress.Length, &progress.Correct, &progress.Time)
+	if errors.Is(err, sql.ErrNoRows) {
+		return progress, nil
 	}
-	defer db.Close()
+	return progress, err
+}
 
-	tx, err := db.Begin()
+// GetAllProgress returns the progress of every file that has been typed
+func (d *DB) GetAllProgress() ([]*Progress, error) {
+	query := `SELECT file, position, length, correct, time FROM progress ORDER BY file`
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var progress []*Progress
+	for rows.Next() {
+		var p Progress
+		err := rows.Scan(&p.File, &p.Position, &p.Length, &p.Correct, &p.Time)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, &p)
+	}
+	return progress, rows.Err()
+}
+
+// SaveProgress stores the new position in a file and adds the result of the test to its totals
+func (d *DB) SaveProgress(file string, position int, length int, result Result) error {
+	query := `INSERT INTO progress(file, position, length, correct, time) VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT(file) DO UPDATE SET position=$2, length=$3, correct=correct+$4, time=time+$5`
+	_, err := d.db.Exec(query, file, position, length, result.Correct, result.TimeTaken)
+	return err
+}
+
+func (d *DB) Save(result Result, charStats map[rune]CharStat, nGramStats map[string]NGramStat) error {
+	tx, err := d.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	query := `INSERT INTO stats(wpm, accuracy, correct, total, mistakes, time) VALUES($1, $2, $3, $4, $5, $6)`
-	_, err = tx.Exec(query, result.WPM, result.Accuracy, result.Correct, result.Total, result.Mistakes, result.TimeTaken)
+	quoteID := sql.NullInt64{Int64: int64(result.QuoteID), Valid: result.QuoteID != 0}
+	failReason := sql.NullString{String: result.FailReason, Valid: result.FailReason != ""}
+	intervalID := sql.NullInt64{Int64: int64(result.IntervalID), Valid: result.IntervalID != 0}
+	query := `INSERT INTO stats(wpm, accuracy, correct, total, mistakes, time, pauses, paused, mode, quote_id, failed, fail_reason, interval_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	res, err := tx.Exec(query, result.WPM, result.Accuracy, result.Correct, result.Total, result.Mistakes, result.TimeTaken, result.Pauses, result.PausedTime, result.Mode, quoteID, failReason.Valid, failReason, intervalID)
 	if err != nil {
 		return err
 	}
 
+	if result.Text != "" && len(result.Times) > 0 {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		times, err := json.Marshal(result.Times)
+		if err != nil {
+			return err
+		}
+		query = `INSERT INTO keystrokes(stats_id, text, times) VALUES($1, $2, $3)`
+		_, err = tx.Exec(query, id, result.Text, times)
+		if err != nil {
+			return err
+		}
+	}
+
 	query = `INSERT INTO chars(char, correct, incorrect) VALUES($1, $2, $3) ON CONFLICT(char) DO UPDATE SET correct=correct+$2, incorrect=incorrect+$3`
 	statment, err := tx.Prepare(query)
 	if err != nil {
@@ -141,6 +494,17 @@ func Save(result Result, charStats map[rune]CharStat, dbFile string) error {
 		return err
 	}
 
-	tx.Commit()
-	return nil
+	for nGram, v := range nGramStats {
+		table, ok := nGramTables[utf8.RuneCountInString(nGram)]
+		if !ok {
+			continue
+		}
+		query = fmt.Sprintf(`INSERT INTO %s(ngram, count, errors, time) VALUES($1, $2, $3, $4) ON CONFLICT(ngram) DO UPDATE SET count=count+$2, errors=errors+$3, time=time+$4`, table)
+		_, err = tx.Exec(query, nGram, v.Count, v.Errors, v.Time)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/words"
+)
+
+// drillCount is how many of the slowest bigrams and trigrams are drilled
+const drillCount = 5
+
+// drillSource picks words containing the slowest n-grams, the more of them a word contains the more often it is picked
+func drillSource(stats *db.DB, wordList []string, strength float64) (words.Source, string, error) {
+	var focus []string
+	for n := 2; n <= 3; n++ {
+		nGramStats, err := stats.GetNGramStats(n)
+		if err != nil {
+			return nil, "", err
+		}
+		focus = append(focus, slowNGrams(nGramStats, wordList)...)
+	}
+	if len(focus) == 0 {
+		return words.NewList(wordList), "", nil
+	}
+
+	// only words with something to drill are used
+	var drillWords []string
+	for _, word := range wordList {
+		for _, f := range focus {
+			if strings.Contains(word, f) {
+				drillWords = append(drillWords, word)
+				break
+			}
+		}
+	}
+	return words.NewWeighted(drillWords, focus, strength), "drill: " + strings.Join(focus, " "), nil
+}
+
+// slowNGrams returns the n-grams with the highest average latency that appear inside words of the word list
+func slowNGrams(nGramStats []*db.NGramStat, wordList []string) []string {
+	var slow []*db.NGramStat
+	for _, v := range nGramStats {
+		if v.Count < minSamples {
+			continue
+		}
+		for _, word := range wordList {
+			if strings.Contains(word, v.NGram) {
+				slow = append(slow, v)
+				break
+			}
+		}
+	}
+	sort.Slice(slow, func(i, j int) bool {
+		return slow[i].Latency() > slow[j].Latency()
+	})
+
+	var nGrams []string
+	for _, v := range slow[:min(drillCount, len(slow))] {
+		nGrams = append(nGrams, v.NGram)
+	}
+	return nGrams
+}
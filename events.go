@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fr3dr/termtyper/input"
+	"github.com/fr3dr/termtyper/session"
+	"golang.org/x/term"
+)
+
+// events starts the goroutines that feed a session with keys, ticks, resizes and continues.
+// They run until ctx is done, errors and quit signals cancel ctx with a cause.
+func events(ctx context.Context, cancel context.CancelCauseFunc, tty *terminal, maxLineLength int) session.Events {
+	// quit when asked to by a signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT)
+	go func() {
+		defer signal.Stop(quit)
+		select {
+		case sig := <-quit:
+			cancel(fmt.Errorf("received signal: %v", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	// read keys
+	keys := make(chan session.Key)
+	go func() {
+		// only restores the mode, output is left to the main goroutine
+		defer tty.cleanup()
+		decoder := input.NewDecoder(tty.in)
+		for {
+			ev, err := decoder.Next()
+			if err != nil {
+				cancel(fmt.Errorf("failed to read input: %w", err))
+				return
+			}
+			if key, ok := sessionKey(ev); ok {
+				select {
+				case keys <- key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// suspend when stopped from outside like after ctrl-z,
+	// so the timer is paused and the terminal restored before the process stops
+	tstp := make(chan os.Signal, 1)
+	signal.Notify(tstp, syscall.SIGTSTP)
+	go func() {
+		defer signal.Stop(tstp)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tstp:
+				select {
+				case keys <- session.Key{Type: session.KeySuspend}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// reflow the text when the terminal is resized
+	resize := make(chan int)
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(winch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-winch:
+				width, _, err := term.GetSize(tty.fd)
+				if err != nil {
+					continue
+				}
+				select {
+				case resize <- fitLineLength(maxLineLength, width):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// redraw after being continued, the redraw happens in the event loop
+	cont := make(chan struct{})
+	sigcont := make(chan os.Signal, 1)
+	signal.Notify(sigcont, syscall.SIGCONT)
+	go func() {
+		defer signal.Stop(sigcont)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigcont:
+				if err := tty.makeRaw(); err != nil {
+					cancel(fmt.Errorf("failed to restore raw mode: %w", err))
+					return
+				}
+				select {
+				case cont <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// update info line
+	ticker := time.NewTicker(100 * time.Millisecond)
+	go func() {
+		<-ctx.Done()
+		ticker.Stop()
+	}()
+
+	return session.Events{
+		Keys:     keys,
+		Ticks:    ticker.C,
+		Resize:   resize,
+		Continue: cont,
+	}
+}
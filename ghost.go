@@ -0,0 +1,35 @@
+package main
+
+import (
+	"time"
+
+	"github.com/fr3dr/termtyper/config"
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/session"
+)
+
+// newGhost returns the ghost to race in a test, the best previous run of the same text
+// or a pacer at the configured speed, nil if there is neither
+func newGhost(cfg config.Config, stats *db.DB, t test) (session.Ghost, error) {
+	// a ghost would give the text away in memory mode
+	if t.zen || t.memorize > 0 {
+		return nil, nil
+	}
+	if cfg.Ghost && t.textKey() != "" {
+		times, err := stats.GetBestTimes(t.textKey())
+		if err != nil {
+			return nil, err
+		}
+		if times != nil {
+			replay := make(session.Replay, len(times))
+			for i, v := range times {
+				replay[i] = time.Duration(v * float64(time.Second))
+			}
+			return replay, nil
+		}
+	}
+	if cfg.Pace > 0 {
+		return session.Pacer(cfg.Pace), nil
+	}
+	return nil, nil
+}
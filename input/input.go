@@ -0,0 +1,308 @@
+package input
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyUnknown
+)
+
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+// Event is a single decoded key press.
+// Rune is only set for KeyRune, control characters are reported
+// as their lowercase letter with ModCtrl.
+type Event struct {
+	Key  Key
+	Rune rune
+	Mod  Mod
+}
+
+// Is reports whether the event is the given rune with exactly the given modifiers
+func (e Event) Is(r rune, mod Mod) bool {
+	return e.Key == KeyRune && e.Rune == r && e.Mod == mod
+}
+
+// EscapeTimeout is how long to wait for the rest of an escape sequence
+// before treating a lone escape byte as the escape key
+const EscapeTimeout = 25 * time.Millisecond
+
+type chunk struct {
+	data []byte
+	err  error
+}
+
+// Decoder turns a raw terminal byte stream into key events
+type Decoder struct {
+	buf     []byte
+	err     error
+	chunks  chan chunk
+	timeout time.Duration
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{
+		chunks:  make(chan chunk),
+		timeout: EscapeTimeout,
+	}
+	go func() {
+		for {
+			b := make([]byte, 256)
+			n, err := r.Read(b)
+			d.chunks <- chunk{b[:n], err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return d
+}
+
+// Next blocks until the next event is available
+func (d *Decoder) Next() (Event, error) {
+	for {
+		if len(d.buf) > 0 {
+			if ev, n := Parse(d.buf); n > 0 {
+				d.buf = d.buf[n:]
+				if ev.Key == KeyUnknown {
+					continue
+				}
+				return ev, nil
+			}
+		}
+
+		// the reader is done, nothing will complete the sequence
+		if d.err != nil {
+			if len(d.buf) == 0 {
+				return Event{}, d.err
+			}
+			ev, n := flush(d.buf)
+			d.buf = d.buf[n:]
+			if ev.Key != KeyUnknown {
+				return ev, nil
+			}
+			continue
+		}
+
+		// wait for more input, an incomplete sequence is flushed after the timeout
+		var timeout <-chan time.Time
+		if len(d.buf) > 0 {
+			timeout = time.After(d.timeout)
+		}
+		select {
+		case c := <-d.chunks:
+			d.buf = append(d.buf, c.data...)
+			d.err = c.err
+		case <-timeout:
+			ev, n := flush(d.buf)
+			d.buf = d.buf[n:]
+			if ev.Key != KeyUnknown {
+				return ev, nil
+			}
+		}
+	}
+}
+
+// flush decodes an incomplete sequence that will not be completed
+func flush(buf []byte) (Event, int) {
+	if buf[0] == 27 {
+		return Event{Key: KeyEscape}, 1
+	}
+	return Event{Key: KeyUnknown}, len(buf)
+}
+
+// Parse decodes the first event in buf and returns it with the number of bytes used.
+// n is 0 if buf only contains the start of an event.
+func Parse(buf []byte) (ev Event, n int) {
+	if len(buf) == 0 {
+		return Event{}, 0
+	}
+
+	b := buf[0]
+	switch {
+	case b == 27:
+		return parseEscape(buf)
+	case b == '\r' || b == '\n':
+		return Event{Key: KeyEnter}, 1
+	case b == '\t':
+		return Event{Key: KeyTab}, 1
+	case b == 127:
+		return Event{Key: KeyBackspace}, 1
+	case b == 8: // most terminals send ctrl-h for ctrl-backspace
+		return Event{Key: KeyBackspace, Mod: ModCtrl}, 1
+	case b == 0:
+		return Event{Key: KeyRune, Rune: ' ', Mod: ModCtrl}, 1
+	case b < 27:
+		return Event{Key: KeyRune, Rune: rune('a' + b - 1), Mod: ModCtrl}, 1
+	case b < 32:
+		return Event{Key: KeyRune, Rune: rune('\\' + b - 28), Mod: ModCtrl}, 1
+	case !utf8.FullRune(buf):
+		return Event{}, 0
+	}
+
+	r, size := utf8.DecodeRune(buf)
+	if r == utf8.RuneError {
+		return Event{Key: KeyUnknown}, size
+	}
+	return Event{Key: KeyRune, Rune: r}, size
+}
+
+func parseEscape(buf []byte) (Event, int) {
+	if len(buf) < 2 {
+		return Event{}, 0
+	}
+
+	switch buf[1] {
+	case '[':
+		return parseCSI(buf)
+	case 'O':
+		if len(buf) < 3 {
+			return Event{}, 0
+		}
+		if key, ok := ss3Keys[buf[2]]; ok {
+			return Event{Key: key}, 3
+		}
+		return Event{Key: KeyUnknown}, 3
+	case 27:
+		return Event{Key: KeyEscape, Mod: ModAlt}, 2
+	}
+
+	// escape followed by a key means alt was held
+	ev, n := Parse(buf[1:])
+	if n == 0 {
+		return ev, 0
+	}
+	ev.Mod |= ModAlt
+	return ev, n + 1
+}
+
+var ss3Keys = map[byte]Key{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+var tildeKeys = map[int]Key{
+	1:  KeyHome,
+	2:  KeyInsert,
+	3:  KeyDelete,
+	4:  KeyEnd,
+	5:  KeyPageUp,
+	6:  KeyPageDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// maxSequence is the longest control sequence that is waited for
+const maxSequence = 32
+
+func parseCSI(buf []byte) (Event, int) {
+	// find the final byte of the sequence
+	end := -1
+	for i := 2; i < len(buf) && i < maxSequence; i++ {
+		if buf[i] >= 0x40 && buf[i] <= 0x7e {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		if len(buf) >= maxSequence {
+			return Event{Key: KeyUnknown}, len(buf)
+		}
+		return Event{}, 0
+	}
+
+	var params []int
+	for _, p := range strings.Split(string(buf[2:end]), ";") {
+		v, _ := strconv.Atoi(p)
+		params = append(params, v)
+	}
+
+	var mod Mod
+	if len(params) > 1 && params[1] > 1 {
+		m := params[1] - 1
+		if m&1 != 0 {
+			mod |= ModShift
+		}
+		if m&2 != 0 {
+			mod |= ModAlt
+		}
+		if m&4 != 0 {
+			mod |= ModCtrl
+		}
+	}
+
+	final := buf[end]
+	switch {
+	case final == '~':
+		if key, ok := tildeKeys[params[0]]; ok {
+			return Event{Key: key, Mod: mod}, end + 1
+		}
+	case final == 'Z':
+		return Event{Key: KeyTab, Mod: ModShift}, end + 1
+	default:
+		if key, ok := ss3Keys[final]; ok {
+			return Event{Key: key, Mod: mod}, end + 1
+		}
+	}
+	return Event{Key: KeyUnknown}, end + 1
+}
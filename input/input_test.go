@@ -0,0 +1,141 @@
+package input
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Event
+		n    int
+	}{
+		{"rune", "a", Event{Key: KeyRune, Rune: 'a'}, 1},
+		{"utf-8", "日x", Event{Key: KeyRune, Rune: '日'}, 3},
+		{"partial utf-8", "\xe6\x97", Event{}, 0},
+		{"invalid utf-8", "\xffa", Event{Key: KeyUnknown}, 1},
+		{"enter", "\r", Event{Key: KeyEnter}, 1},
+		{"tab", "\t", Event{Key: KeyTab}, 1},
+		{"backspace", "\x7f", Event{Key: KeyBackspace}, 1},
+		{"ctrl-h", "\x08", Event{Key: KeyBackspace, Mod: ModCtrl}, 1},
+		{"ctrl-c", "\x03", Event{Key: KeyRune, Rune: 'c', Mod: ModCtrl}, 1},
+		{"ctrl-space", "\x00", Event{Key: KeyRune, Rune: ' ', Mod: ModCtrl}, 1},
+		{"ctrl-backslash", "\x1c", Event{Key: KeyRune, Rune: '\\', Mod: ModCtrl}, 1},
+		{"lone escape", "\x1b", Event{}, 0},
+		{"alt-rune", "\x1bx", Event{Key: KeyRune, Rune: 'x', Mod: ModAlt}, 2},
+		{"alt-backspace", "\x1b\x7f", Event{Key: KeyBackspace, Mod: ModAlt}, 2},
+		{"alt-escape", "\x1b\x1b", Event{Key: KeyEscape, Mod: ModAlt}, 2},
+		{"csi arrow", "\x1b[A", Event{Key: KeyUp}, 3},
+		{"csi ctrl-up", "\x1b[1;5A", Event{Key: KeyUp, Mod: ModCtrl}, 6},
+		{"csi shift-alt-right", "\x1b[1;4C", Event{Key: KeyRight, Mod: ModShift | ModAlt}, 6},
+		{"csi delete", "\x1b[3~", Event{Key: KeyDelete}, 4},
+		{"csi ctrl-delete", "\x1b[3;5~", Event{Key: KeyDelete, Mod: ModCtrl}, 6},
+		{"csi f12", "\x1b[24~", Event{Key: KeyF12}, 5},
+		{"csi unknown tilde code", "\x1b[99~a", Event{Key: KeyUnknown}, 5},
+		{"csi shift-tab", "\x1b[Z", Event{Key: KeyTab, Mod: ModShift}, 3},
+		{"csi incomplete", "\x1b[1;5", Event{}, 0},
+		{"csi too long", "\x1b[" + strings.Repeat("1", 40), Event{Key: KeyUnknown}, 42},
+		{"ss3 f1", "\x1bOP", Event{Key: KeyF1}, 3},
+		{"ss3 f4", "\x1bOS", Event{Key: KeyF4}, 3},
+		{"ss3 home", "\x1bOH", Event{Key: KeyHome}, 3},
+		{"ss3 incomplete", "\x1bO", Event{}, 0},
+		{"ss3 unknown", "\x1bOz", Event{Key: KeyUnknown}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, n := Parse([]byte(tt.in))
+			if ev != tt.want || n != tt.n {
+				t.Errorf("Parse(%q) = %+v, %d, want %+v, %d", tt.in, ev, n, tt.want, tt.n)
+			}
+		})
+	}
+}
+
+// next returns the next event of d or fails if there is none within a second
+func next(t *testing.T, d *Decoder) (Event, error) {
+	t.Helper()
+	type result struct {
+		ev  Event
+		err error
+	}
+	c := make(chan result, 1)
+	go func() {
+		ev, err := d.Next()
+		c <- result{ev, err}
+	}()
+	select {
+	case r := <-c:
+		return r.ev, r.err
+	case <-time.After(time.Second):
+		t.Fatal("no event within a second")
+		return Event{}, nil
+	}
+}
+
+func TestDecoderLoneEscape(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	d := NewDecoder(r)
+	go w.Write([]byte("\x1b"))
+
+	start := time.Now()
+	ev, err := next(t, d)
+	if err != nil || ev != (Event{Key: KeyEscape}) {
+		t.Fatalf("Next() = %+v, %v, want escape", ev, err)
+	}
+	if waited := time.Since(start); waited < EscapeTimeout {
+		t.Errorf("escape flushed after %v, before the timeout", waited)
+	}
+}
+
+func TestDecoderSplitSequence(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	d := NewDecoder(r)
+	// the rest of the sequence always arrives in time
+	d.timeout = time.Minute
+	go func() {
+		for _, part := range []string{"\x1b[1", ";5", "Ax"} {
+			w.Write([]byte(part))
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	for _, want := range []Event{{Key: KeyUp, Mod: ModCtrl}, {Key: KeyRune, Rune: 'x'}} {
+		ev, err := next(t, d)
+		if err != nil || ev != want {
+			t.Fatalf("Next() = %+v, %v, want %+v", ev, err, want)
+		}
+	}
+}
+
+func TestDecoderEOF(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Event
+	}{
+		{"incomplete csi", "a\x1b[", []Event{{Key: KeyRune, Rune: 'a'}, {Key: KeyEscape}, {Key: KeyRune, Rune: '['}}},
+		{"incomplete utf-8", "a\xe6\x97", []Event{{Key: KeyRune, Rune: 'a'}}},
+		{"lone escape", "\x1b", []Event{{Key: KeyEscape}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.in))
+			d.timeout = time.Minute
+			for _, want := range tt.want {
+				ev, err := next(t, d)
+				if err != nil || ev != want {
+					t.Fatalf("Next() = %+v, %v, want %+v", ev, err, want)
+				}
+			}
+			if _, err := next(t, d); err != io.EOF {
+				t.Errorf("Next() error = %v, want EOF", err)
+			}
+		})
+	}
+}
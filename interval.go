@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/render"
+	"github.com/fr3dr/termtyper/session"
+	"github.com/fr3dr/termtyper/target"
+)
+
+// defaultSprint is how long a sprint takes in interval mode without timed mode
+const defaultSprint = 15
+
+// intervalSet is a set of sprints with rests in between, saved as one group of results
+type intervalSet struct {
+	id      int
+	results []db.Result
+}
+
+// rest counts down the rest before a sprint, it can be skipped with tab.
+// It returns true if the program should quit.
+func rest(ctx context.Context, r render.Renderer, ev session.Events, suspend func(), lineLength *int, d time.Duration, sprint int, sprints int) (bool, error) {
+	end := time.Now().Add(d)
+	info := func(now time.Time) string {
+		return fmt.Sprintf("rest %s, sprint %d/%d starts next  tab: skip  ctrl-c: quit", end.Sub(now).Round(time.Second), sprint, sprints)
+	}
+	r.SetInfo(info(time.Now()), render.InfoStart)
+	if err := r.Flush(); err != nil {
+		return true, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.Clear()
+			return true, r.Flush()
+		case width := <-ev.Resize:
+			*lineLength = width
+		case <-ev.Continue:
+			r.Reset()
+		case now := <-ev.Ticks:
+			if !now.Before(end) {
+				return false, nil
+			}
+			r.SetInfo(info(now), render.InfoStart)
+		case key := <-ev.Keys:
+			switch key.Type {
+			case session.KeyQuit:
+				r.Clear()
+				return true, r.Flush()
+			case session.KeyTab, session.KeyRestart:
+				return false, nil
+			case session.KeySuspend:
+				// the rest does not go on while the program is stopped
+				stopped := time.Now()
+				suspend()
+				end = end.Add(time.Since(stopped))
+			}
+		}
+		if err := r.Flush(); err != nil {
+			return true, err
+		}
+	}
+}
+
+// showIntervals draws the result of every sprint of a set and how much slower the last one was than the first
+func showIntervals(r render.Renderer, results []db.Result) error {
+	var lines []string
+	var sumWPM, sumAccuracy float64
+	for i, v := range results {
+		lines = append(lines, fmt.Sprintf("sprint %-3d %03.0fwpm  %.2f%%", i+1, v.WPM, v.Accuracy))
+		sumWPM += v.WPM
+		sumAccuracy += v.Accuracy
+	}
+	n := float64(len(results))
+	lines = append(lines, fmt.Sprintf("average    %03.0fwpm  %.2f%%", sumWPM/n, sumAccuracy/n))
+	first, last := results[0].WPM, results[len(results)-1].WPM
+	if first > 0 {
+		lines = append(lines, fmt.Sprintf("drop-off   %+.1f%% from the first to the last sprint", (last-first)/first*100))
+	}
+
+	r.DrawText(target.New(lines))
+	r.SetInfo(fmt.Sprintf("%d sprints done", len(results)), render.InfoDone)
+	r.Finish()
+	return r.Flush()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"unicode"
+
+	"github.com/fr3dr/termtyper/input"
+	"github.com/fr3dr/termtyper/session"
+)
+
+// sessionKey maps an input event to the session key it is bound to
+func sessionKey(ev input.Event) (session.Key, bool) {
+	switch {
+	case ev.Is('c', input.ModCtrl):
+		return session.Key{Type: session.KeyQuit}, true
+	case ev.Key == input.KeyEscape && ev.Mod == 0:
+		return session.Key{Type: session.KeyPause}, true
+	case ev.Key == input.KeyTab && ev.Mod == 0:
+		return session.Key{Type: session.KeyTab}, true
+	case ev.Is('r', input.ModCtrl):
+		return session.Key{Type: session.KeyRestart}, true
+	case ev.Key == input.KeyEnter && ev.Mod == 0:
+		return session.Key{Type: session.KeyRune, Rune: '\n'}, true
+	case ev.Is('d', input.ModCtrl):
+		return session.Key{Type: session.KeyFinish}, true
+	case ev.Is('z', input.ModCtrl):
+		return session.Key{Type: session.KeySuspend}, true
+	case ev.Key == input.KeyBackspace && ev.Mod == 0:
+		return session.Key{Type: session.KeyBackspace}, true
+	case ev.Key == input.KeyBackspace, ev.Is('w', input.ModCtrl): // ctrl-backspace, alt-backspace and ctrl-w
+		return session.Key{Type: session.KeyDeleteWord}, true
+	case ev.Key == input.KeyRune && ev.Mod&^input.ModShift == 0 && unicode.IsGraphic(ev.Rune):
+		return session.Key{Type: session.KeyRune, Rune: ev.Rune}, true
+	}
+	return session.Key{}, false
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/lessons"
+)
+
+// unlocked reports whether a lesson can be practiced, the first one always can
+// and every other one once the lesson before it is passed
+func unlocked(i int, progress map[int]db.LessonProgress) bool {
+	return i == 0 || progress[lessons.All[i-1].ID].Passed
+}
+
+// findLesson returns the lesson with id if it is unlocked
+func findLesson(stats *db.DB, id int) (*lessons.Lesson, error) {
+	progress, err := stats.GetLessonProgress()
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range lessons.All {
+		if l.ID != id {
+			continue
+		}
+		if !unlocked(i, progress) {
+			return nil, fmt.Errorf("lesson %d is locked, pass lesson %d first", id, lessons.All[i-1].ID)
+		}
+		return &l, nil
+	}
+	return nil, fmt.Errorf("there is no lesson %d", id)
+}
+
+// nextLesson returns the lesson after l, nil after the last one
+func nextLesson(l *lessons.Lesson) *lessons.Lesson {
+	for i := range lessons.All[:len(lessons.All)-1] {
+		if lessons.All[i].ID == l.ID {
+			return &lessons.All[i+1]
+		}
+	}
+	return nil
+}
+
+// lessonInfo is shown after the result of a lesson
+func lessonInfo(l *lessons.Lesson) string {
+	return fmt.Sprintf("lesson %d: %s, pass with %gwpm and %g%%", l.ID, l.Name, l.MinWPM, l.MinAccuracy)
+}
+
+// showLessons prints every lesson with what it takes to pass it and how far it got
+func showLessons(stats *db.DB) error {
+	progress, err := stats.GetLessonProgress()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "lesson \tname \tpass \tbest \truns \tstatus")
+	fmt.Fprintln(w, "------ \t---- \t---- \t---- \t---- \t------")
+	for i, l := range lessons.All {
+		p := progress[l.ID]
+		status := "locked"
+		switch {
+		case p.Passed:
+			status = "passed"
+		case unlocked(i, progress):
+			status = "unlocked"
+		}
+		best := "-"
+		if p.Runs > 0 {
+			best = fmt.Sprintf("%.0fwpm %.2f%%", p.BestWPM, p.BestAccuracy)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%gwpm %g%%\t%s\t%d\t%s\n", l.ID, l.Name, l.MinWPM, l.MinAccuracy, best, p.Runs, status)
+	}
+	w.Flush()
+	fmt.Println("\nStart a lesson with -lesson <lesson>")
+	return nil
+}
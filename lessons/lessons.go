@@ -0,0 +1,133 @@
+package lessons
+
+import (
+	"math/rand/v2"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fr3dr/termtyper/words"
+)
+
+// Kind is what a lesson practices besides letters
+type Kind int
+
+const (
+	// Letters lessons only use words of their keys
+	Letters Kind = iota
+	// Shift lessons capitalise half of the words
+	Shift
+	// Numbers lessons mix words with numbers
+	Numbers
+	// Symbols lessons mix words with tokens of digits and symbols
+	Symbols
+)
+
+// Lesson practices a set of keys until it is typed fast and accurately enough to pass
+type Lesson struct {
+	ID   int
+	Name string
+	// Keys are the letters words may contain
+	Keys string
+	Kind Kind
+	// the result needed to pass the lesson and unlock the next one
+	MinWPM      float64
+	MinAccuracy float64
+}
+
+// letters are all the letter keys
+const letters = "asdfghjklqwertyuiopzxcvbnm"
+
+// All is the curriculum in the order the lessons are unlocked, every lesson includes the keys of the ones before it
+var All = []Lesson{
+	{ID: 1, Name: "home row", Keys: "asdfghjkl", MinWPM: 15, MinAccuracy: 95},
+	{ID: 2, Name: "top row", Keys: "asdfghjklqwertyuiop", MinWPM: 20, MinAccuracy: 95},
+	{ID: 3, Name: "bottom row", Keys: letters, MinWPM: 25, MinAccuracy: 95},
+	{ID: 4, Name: "shift", Keys: letters, Kind: Shift, MinWPM: 25, MinAccuracy: 94},
+	{ID: 5, Name: "numbers", Keys: letters, Kind: Numbers, MinWPM: 25, MinAccuracy: 93},
+	{ID: 6, Name: "symbols", Keys: letters, Kind: Symbols, MinWPM: 25, MinAccuracy: 92},
+}
+
+// Passed reports whether a result is good enough to pass the lesson
+func (l Lesson) Passed(wpm, accuracy float64) bool {
+	return wpm >= l.MinWPM && accuracy >= l.MinAccuracy
+}
+
+// minWords is how many words of the word list a lesson needs before made up words are used instead
+const minWords = 20
+
+// Source returns the words of the lesson, built from the words of the word list that only use its keys
+// or made up words of its keys if the word list has too few of them
+func (l Lesson) Source(wordList []string) words.Source {
+	src := l.words(wordList)
+	switch l.Kind {
+	case Shift:
+		return shifted{src}
+	case Numbers:
+		// the sets are never empty
+		numbers, _ := words.NewSymbols("0123456789")
+		return mixed{words: shifted{src}, tokens: numbers}
+	case Symbols:
+		symbols, _ := words.NewSymbols(words.DefaultSymbolSet)
+		return mixed{words: shifted{src}, tokens: symbols}
+	default:
+		return src
+	}
+}
+
+// words returns the words of the word list that only use the keys of the lesson,
+// or made up words of its keys if there are too few of them
+func (l Lesson) words(wordList []string) words.Source {
+	var matching []string
+	for _, word := range wordList {
+		if strings.Trim(word, l.Keys) == "" {
+			matching = append(matching, word)
+		}
+	}
+	if len(matching) >= minWords {
+		return words.NewList(matching)
+	}
+	return madeUp([]rune(l.Keys))
+}
+
+// shifted capitalises half of the words and writes some in all capitals
+type shifted struct {
+	src words.Source
+}
+
+func (s shifted) Next() string {
+	word := s.src.Next()
+	switch n := rand.IntN(10); {
+	case n == 0:
+		return strings.ToUpper(word)
+	case n < 5:
+		r, size := utf8.DecodeRuneInString(word)
+		return string(unicode.ToUpper(r)) + word[size:]
+	default:
+		return word
+	}
+}
+
+// mixed picks tokens two out of three times and words otherwise
+type mixed struct {
+	words  words.Source
+	tokens words.Source
+}
+
+func (m mixed) Next() string {
+	if rand.IntN(3) == 0 {
+		return m.words.Next()
+	}
+	return m.tokens.Next()
+}
+
+// madeUp makes up words of 2 to 5 random keys
+type madeUp []rune
+
+func (m madeUp) Next() string {
+	word := make([]rune, 2+rand.IntN(4))
+	for i := range word {
+		word[i] = m[rand.IntN(len(m))]
+	}
+	return string(word)
+}
@@ -0,0 +1,42 @@
+package lessons
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestSource(t *testing.T) {
+	wordList := []string{"as", "dad", "sad", "fall", "glass", "the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog"}
+	for _, l := range All {
+		t.Run(l.Name, func(t *testing.T) {
+			src := l.Source(wordList)
+			var upper, digits, symbols int
+			for range 1000 {
+				word := src.Next()
+				for _, r := range word {
+					switch {
+					case unicode.IsUpper(r):
+						upper++
+					case unicode.IsDigit(r):
+						digits++
+					case !unicode.IsLetter(r):
+						symbols++
+					}
+				}
+				if l.Kind == Letters && strings.Trim(word, l.Keys) != "" {
+					t.Fatalf("%q uses keys outside of %q", word, l.Keys)
+				}
+			}
+			if (l.Kind >= Shift) != (upper > 0) {
+				t.Errorf("%d capitals", upper)
+			}
+			if (l.Kind >= Numbers) != (digits > 0) {
+				t.Errorf("%d digits", digits)
+			}
+			if (l.Kind == Symbols) != (symbols > 0) {
+				t.Errorf("%d symbols", symbols)
+			}
+		})
+	}
+}
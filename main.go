@@ -5,41 +5,19 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math/rand/v2"
 	"os"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/fr3dr/termtyper/config"
 	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/render"
+	"github.com/fr3dr/termtyper/session"
+	"github.com/fr3dr/termtyper/words"
 	"golang.org/x/term"
 )
 
-// ANSI color escape codes
-var (
-	resetColor      = "\033[0m"
-	backgroundColor = "\033[90m"
-	infoStartColor  = "\033[2;92m"
-	infoColor       = "\033[92m"
-	infoDoneColor   = "\033[2;33m"
-	typedColor      = "\033[97m"
-	errorColor      = "\033[1;4;31m"
-)
-
-// xterm cursor shape escape codes
-var (
-	defaultCursor   = "\033[0 q"
-	blockCursor     = "\033[1 q"
-	underlineCursor = "\033[3 q"
-	barCursor       = "\033[5 q"
-)
-
-var wordsString string
-
-// TODO: show mistyped chars
 // TODO: dont generate words longer than maxLineLength
-// TODO: track more stats like time taken to type character
 // TODO: config documentation
 // TODO: multiplayer racing
 // TODO: better stats display
@@ -52,24 +30,21 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// get terminal info
-	termHandle := int(os.Stderr.Fd())
-	width, _, err := term.GetSize(termHandle)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	// get config with default config
 	cfg, err := config.GetConfig(config.Config{
 		WordCount:       25,
 		WordListAmmount: len(wordList),
-		MaxLineLength:   width,
+		MaxLineLength:   0,
 		TimedMode:       0,
 		NoBackspace:     false,
 		CorrectOnly:     false,
 		ShowStats:       false,
 		CursorShape:     "",
 		WordListFile:    "",
+		// words with a weak character are picked 4 times as often
+		AdaptiveStrength: 3,
+		Rest:             10,
+		SymbolSet:        words.DefaultSymbolSet,
 	})
 	if err != nil {
 		log.Fatalf("Failed to get config: %v", err)
@@ -84,7 +59,10 @@ func main() {
 		wordList = []string{}
 		scanner := bufio.NewScanner(file)
 		for scanner.Scan() {
-			wordList = append(wordList, scanner.Text())
+			// skip empty lines and strip windows line endings
+			if word := strings.TrimSpace(scanner.Text()); word != "" {
+				wordList = append(wordList, word)
+			}
 		}
 		if scanner.Err() != nil {
 			log.Fatalf("Error while reading word list file: %v", err)
@@ -92,339 +70,258 @@ func main() {
 		cfg.WordListAmmount = len(wordList)
 	}
 
-	if cfg.ShowStats {
-		// get stats from database
-		results, charStats, err := db.GetAll(dbFile)
+	// keys are read from the controlling terminal when stdin is used for the text
+	var piped *book
+	in := os.Stdin
+	termHandle := int(os.Stderr.Fd())
+	if cfg.Stdin {
+		piped, err = readBook(os.Stdin)
 		if err != nil {
-			log.Fatalf("Failed to get stats: %v", err)
+			log.Fatalf("Failed to read stdin: %v", err)
 		}
-
-		// print char stats
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-		fmt.Fprintln(w, "char \tcorrect \tincorrect \taccuracy")
-		fmt.Fprintln(w, "---- \t------- \t--------- \t--------")
-		for _, v := range charStats {
-			fmt.Fprintf(w, "%c\t%d\t%d\t%.2f%%\n", v.Char, v.Correct, v.Incorrect, v.Accuracy)
+		in, err = os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			log.Fatalf("Failed to open terminal: %v", err)
 		}
-		w.Flush()
+		defer in.Close()
+		termHandle = int(in.Fd())
+	}
 
-		// print general stats
-		var ammount float64
-		var sumWPM float64
-		var sumAccuracy float64
-		var sumMistakes float64
-		var totalTime time.Duration
-		for _, v := range results {
-			ammount++
-			sumWPM += v.WPM
-			sumAccuracy += v.Accuracy
-			sumMistakes += float64(v.Mistakes)
-			totalTime += time.Duration(v.TimeTaken) * time.Second
-		}
-		fmt.Printf("Average WPM: %.2f\n", sumWPM/ammount)
-		fmt.Printf("Average Accuracy: %.2f%%\n", sumAccuracy/ammount)
-		fmt.Printf("Average Mistakes: %.2f\n", sumMistakes/ammount)
-		fmt.Printf("Time spent typing: %v\n", totalTime.Round(time.Millisecond))
+	// get terminal info
+	width, height, err := term.GetSize(termHandle)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		return
+	// open stats database, it stays open between tests
+	stats, err := db.Open(dbFile)
+	if err != nil {
+		log.Fatalf("Failed to open stats database: %v", err)
 	}
+	defer stats.Close()
 
-	// set cursor shape
-	switch cfg.CursorShape {
-	case "block":
-		fmt.Printf("%s", blockCursor)
-	case "bar":
-		fmt.Printf("%s", barCursor)
-	case "underline":
-		fmt.Printf("%s", underlineCursor)
+	switch cfg.Command {
+	case "", "-":
+	case "lessons":
+		err := showLessons(stats)
+		if err != nil {
+			log.Fatalf("Failed to get lessons: %v", err)
+		}
+		return
 	default:
-		fmt.Printf("%s", defaultCursor)
+		log.Fatalf("Unknown command %q", cfg.Command)
 	}
-	defer fmt.Printf("%s", defaultCursor)
 
-	// generate lines
-	line := ""
-	linesNum := 0
-	wordIndex := 0
-	var lines []string
-	for {
-		word := wordList[rand.IntN(cfg.WordListAmmount)]
-		wordIndex++
-		if len(line)+len(word)+1 > cfg.MaxLineLength {
-			lines = append(lines, line)
-			wordsString += line
-			line = ""
-			linesNum++
-			if cfg.TimedMode > 0 && linesNum >= 3 {
-				break
-			}
-		}
-		line += word
-		if cfg.TimedMode <= 0 && wordIndex >= cfg.WordCount {
-			lines = append(lines, line)
-			wordsString += line
-			linesNum++
-			break
+	if cfg.ShowStats {
+		err := showStats(stats, cfg.ShowFailed)
+		if err != nil {
+			log.Fatalf("Failed to get stats: %v", err)
 		}
-		line += " "
+		return
 	}
 
-	// print words and placeholder info line
-	printfColor(infoStartColor, "000wpm  0s  0/%d/0  100%%\n", len(wordsString))
-	printfColor(backgroundColor, "%s\r", strings.Join(lines, "\n"))
-
-	// save cursor position at "(0, 0)" and move down to text start
-	fmt.Printf("\033[%dA\0337\033[1B", linesNum)
-
-	// put terminal into raw mode
-	oldState, err := term.MakeRaw(termHandle)
+	wordList = wordList[:min(cfg.WordListAmmount, len(wordList))]
+	src := sources{
+		// leave space for the info line and the prompt
+		rows: min(height-3, 20),
+		book: piped,
+	}
+	src.words, src.focus, err = practiceSource(cfg, stats, wordList)
+	if err != nil {
+		log.Fatalf("Failed to get words to practice: %v", err)
+	}
+	if cfg.Lesson > 0 {
+		src.lesson, err = findLesson(stats, cfg.Lesson)
+		if err != nil {
+			log.Fatal(err)
+		}
+		src.words = src.lesson.Source(wordList)
+	}
+	if cfg.BookFile != "" && piped == nil {
+		src.book, err = loadBook(cfg.BookFile, stats)
+		if err != nil {
+			log.Fatalf("Failed to load book: %v", err)
+		}
+	}
+	if cfg.CodeFile != "" {
+		src.code, err = loadCode(cfg.CodeFile, cfg.TypeIndent)
+		if err != nil {
+			log.Fatalf("Failed to load code file: %v", err)
+		}
+	}
+	lineLength := fitLineLength(cfg.MaxLineLength, width)
+	t, err := newTest(cfg, src, lineLength)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer term.Restore(termHandle, oldState)
 
-	// typing variables
-	firstInput := true
-	cursorIndex := 0
-	cursorRow := 0
-	cursorColumn := 0
-	correct := 0
-	mistakes := 0
-	mistakeMade := false
-	var startTime time.Time
-	var endTime time.Time
-	var typedChars []rune
-	var charStats map[rune]db.CharStat = make(map[rune]db.CharStat, 95)
+	// put terminal into raw mode, it is restored on every way out of main
+	tty := newTerminal(in, termHandle)
+	defer tty.cleanup()
+	defer tty.resetCursor()
+	if err := tty.makeRaw(); err != nil {
+		tty.fatalf("Failed to put terminal into raw mode: %v", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	ev := events(ctx, cancel, tty, cfg.MaxLineLength)
+	r := render.NewANSI(os.Stdout, cursorCode(cfg.CursorShape))
+
+	var set intervalSet
+	for {
+		s := session.New(session.Config{
+			NoBackspace: cfg.NoBackspace,
+			CorrectOnly: cfg.CorrectOnly,
+			TimedMode:   t.timedMode,
+			LineLength:  lineLength,
+			Attribution: t.attribution,
+			Code:        t.code,
+			TypeIndent:  cfg.TypeIndent,
+			Zen:         t.zen,
+			SuddenDeath: cfg.SuddenDeath,
+			MinAccuracy: cfg.MinAccuracy,
+			MinWPM:      cfg.MinWPM,
+			Blind:       cfg.Blind,
+			Memorize:    t.memorize,
+		}, t.lines)
+		if t.words != nil {
+			s.NextLine = func(width int) string {
+				return words.Line(t.words, width)
+			}
+		}
+		s.Reflow(lineLength)
+		s.Suspend = tty.suspend
+		s.Ghost, err = newGhost(cfg, stats, t)
+		if err != nil {
+			tty.fatalf("Failed to get ghost: %v", err)
+		}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	exit := make(chan bool)
+		exit, err := s.Run(ctx, r, ev)
+		if err != nil {
+			tty.fatalf("%v", err)
+		}
+		if err := context.Cause(ctx); err != nil {
+			tty.fatalf("%v", err)
+		}
+		lineLength = s.LineLength()
+
+		switch exit {
+		case session.ExitQuit:
+			return
+		case session.ExitRestart:
+			t, err = newTest(cfg, src, lineLength)
+			if err != nil {
+				tty.fatalf("%v", err)
+			}
+			continue
+		}
 
-	// draw info line
-	go func() {
-		for range time.Tick(100 * time.Millisecond) {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				if firstInput {
-					continue
+		// save result
+		result := s.Result()
+		result.Mode = t.mode
+		result.QuoteID = t.quoteID
+		result.Text = t.textKey()
+		if cfg.Intervals > 0 {
+			if set.id == 0 {
+				set.id, err = stats.StartInterval(cfg.Intervals, t.timedMode.Seconds(), float64(cfg.Rest))
+				if err != nil {
+					tty.fatalf("Failed to save interval: %v", err)
 				}
-				if cfg.TimedMode > 0 && time.Until(startTime.Add(time.Duration(cfg.TimedMode)*time.Second)) <= 0 {
-					endTime = time.Now()
-					cancel()
-					return
+			}
+			result.IntervalID = set.id
+		}
+		err = stats.Save(result, s.CharStats(), s.NGramStats())
+		if err != nil {
+			tty.fatalf("Failed to save result: %v", err)
+		}
+		// what is practiced changes with every result
+		if (cfg.Adaptive || cfg.Drill || cfg.Symbols) && src.lesson == nil {
+			src.words, src.focus, err = practiceSource(cfg, stats, wordList)
+			if err != nil {
+				tty.fatalf("Failed to get words to practice: %v", err)
+			}
+		}
+		// a failed page has to be typed again
+		if src.book != nil && s.Failed() == "" {
+			if src.book.path != "" {
+				err = stats.SaveProgress(src.book.path, t.bookEnd, len(src.book.text), result)
+				if err != nil {
+					tty.fatalf("Failed to save progress: %v", err)
 				}
-				fmt.Printf("\0338\033[2K\r")
-				printfColor(infoColor, "%03.0fwpm  %s  %d/%d/%d  %.2f%%", float64(correct)/5/time.Since(startTime).Minutes(), time.Since(startTime).Round(time.Second), correct, len(wordsString), mistakes, float64(correct)/float64(correct+mistakes)*100)
-				fmt.Printf("\033[%dB\033[%dG", cursorRow+1, cursorColumn+1)
 			}
+			src.book.position = t.bookEnd % len(src.book.text)
 		}
-	}()
 
-	// typing logic
-	go func() {
-		reader := bufio.NewReader(os.Stdin)
-		b := make([]byte, 2)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				if _, err := reader.Read(b); err != nil {
-					log.Fatal(err)
+		// sprints follow each other after a rest until the set is done
+		if cfg.Intervals > 0 {
+			set.results = append(set.results, result)
+			if len(set.results) < cfg.Intervals {
+				quit, err := rest(ctx, r, ev, tty.suspend, &lineLength, time.Duration(cfg.Rest)*time.Second, len(set.results)+1, cfg.Intervals)
+				if err != nil {
+					tty.fatalf("%v", err)
 				}
-				char := rune(b[0])
-
-				// quit on ctrl-c
-				if char == 3 {
-					// clear everything
-					fmt.Printf("\0338")
-					for range linesNum + 1 {
-						fmt.Printf("\033[2K\033[1B")
-					}
-					fmt.Printf("\0338\r")
-					exit <- true
-					cancel()
+				if quit {
 					return
 				}
-
-				switch {
-				case (char == 8 || b[0] == 27 && b[1] == 127) && !cfg.NoBackspace && !cfg.CorrectOnly: // delete word
-					if cursorIndex <= 0 {
-						break
-					}
-
-					deletedString := ""
-					nonWhitespaceFound := false
-					for cursorIndex > 0 && (getChar(cursorIndex - 1) != ' ' || !nonWhitespaceFound) {
-						cursorIndex--
-						cursorColumn--
-
-						if getChar(cursorIndex) != ' ' {
-							nonWhitespaceFound = true
-						}
-						if typedChars[cursorIndex] == getChar(cursorIndex) {
-							correct--
-						}
-
-						// line wraps
-						if cursorColumn < 0 {
-							cursorRow--
-							cursorColumn = len(lines[cursorRow]) - 1
-							fmt.Printf("\033[0G")
-							printfColor(backgroundColor, "%s", deletedString)
-							fmt.Printf("\033[1A\033[%dG", cursorColumn+2)
-							deletedString = ""
-						}
-
-						deletedString = string(getChar(cursorIndex)) + deletedString
-					}
-
-					fmt.Printf("\033[%vD", len(deletedString))
-					printfColor(backgroundColor, "%s", deletedString)
-					fmt.Printf("\033[%vD", len(deletedString))
-
-					typedChars = typedChars[:len(typedChars)-len(deletedString)]
-				case char == 127 && !cfg.NoBackspace && !cfg.CorrectOnly: // backspace
-					// dont backspace out of bounds
-					if cursorIndex <= 0 {
-						break
-					}
-					cursorIndex--
-					cursorColumn--
-
-					// line wrapping
-					if cursorColumn < 0 { // line wraps
-						cursorRow--
-						cursorColumn = len(lines[cursorRow]) - 1
-						fmt.Printf("\033[1A\033[%dG", cursorColumn+1)
-						printfColor(backgroundColor, "%c", getChar(cursorIndex))
-						fmt.Printf("\033[1D")
-					} else { // line does not wrap
-						fmt.Printf("\033[1D")
-						printfColor(backgroundColor, "%c", getChar(cursorIndex))
-						fmt.Printf("\033[1D")
-					}
-
-					if typedChars[cursorIndex] == getChar(cursorIndex) {
-						correct--
-					}
-
-					typedChars = typedChars[:len(typedChars)-1]
-				case char >= 32 && char <= 126:
-					if firstInput {
-						startTime = time.Now()
-						firstInput = false
-					}
-
-					charStat := charStats[getChar(cursorIndex)]
-
-					if !cfg.CorrectOnly || !mistakeMade {
-						typedChars = append(typedChars, char)
-					}
-
-					if getChar(cursorIndex) == char {
-						if cfg.CorrectOnly && mistakeMade {
-							mistakeMade = false
-							if getChar(cursorIndex) == ' ' {
-								printfColor(errorColor, "_")
-							} else {
-								printfColor(errorColor, "%c", getChar(cursorIndex))
-							}
-						} else {
-							correct++
-							charStat.Correct++
-							printfColor(typedColor, "%c", char)
-						}
-					} else {
-						mistakes++
-						charStat.Incorrect++
-						if cfg.CorrectOnly {
-							mistakeMade = true
-							typedChars[cursorIndex] = char
-						} else if getChar(cursorIndex) == ' ' {
-							printfColor(errorColor, "_")
-						} else {
-							printfColor(errorColor, "%c", getChar(cursorIndex))
-						}
-					}
-
-					charStats[getChar(cursorIndex)] = charStat
-
-					if !cfg.CorrectOnly || !mistakeMade {
-						cursorIndex++
-						cursorColumn++
-					}
-
-					// line wrapping
-					if cursorColumn >= len(lines[cursorRow]) && cursorIndex < len(wordsString) {
-						cursorRow++
-						cursorColumn = 0
-						fmt.Printf("\033[1B\r")
-						// add new line in timed mode
-						if cfg.TimedMode > 0 && cursorRow == linesNum-1 {
-							line := ""
-							for {
-								word := wordList[rand.IntN(cfg.WordListAmmount)]
-								if len(line)+len(word)+1 > cfg.MaxLineLength {
-									lines = append(lines, line)
-									wordsString += line
-									linesNum++
-									break
-								}
-								line += word + " "
-							}
-							printfColor(backgroundColor, "\n%s\r", line)
-							fmt.Printf("\033[%dA\0337\033[%dB", linesNum, linesNum-1)
-						}
-					}
+				t, err = newTest(cfg, src, lineLength)
+				if err != nil {
+					tty.fatalf("%v", err)
 				}
+				continue
+			}
+			err = showIntervals(r, set.results)
+			if err != nil {
+				tty.fatalf("%v", err)
+			}
+			set = intervalSet{}
+		}
 
-				// end game
-				if cursorIndex == len(wordsString) || !firstInput && cfg.TimedMode > 0 && time.Until(startTime.Add(time.Duration(cfg.TimedMode)*time.Second)) <= 0 {
-					endTime = time.Now()
-					cancel()
-					return
+		// passing a lesson moves on to the next one
+		var status string
+		if t.lesson != nil {
+			passed := s.Failed() == "" && t.lesson.Passed(result.WPM, result.Accuracy)
+			err = stats.SaveLesson(t.lesson.ID, result, passed)
+			if err != nil {
+				tty.fatalf("Failed to save lesson: %v", err)
+			}
+			status = fmt.Sprintf("lesson %d not passed", t.lesson.ID)
+			if passed {
+				status = fmt.Sprintf("lesson %d passed", t.lesson.ID)
+				if next := nextLesson(t.lesson); next != nil {
+					src.lesson = next
+					src.words = next.Source(wordList)
+					status += fmt.Sprintf(", next up lesson %d: %s", next.ID, next.Name)
 				}
 			}
 		}
-	}()
-
-	select {
-	case <-ctx.Done():
-		break
-	case <-exit:
-		return
-	}
-
-	// stats
-	timeTaken := endTime.Sub(startTime)
-	wpm := float64(correct) / 5 / timeTaken.Minutes()
-	accuracy := float64(correct) / float64(correct+mistakes) * 100
 
-	fmt.Printf("\0338\033[2K\r")
-	printfColor(infoDoneColor, "%03.0fwpm  %s  %d/%d/%d  %.2f%%", wpm, timeTaken.Round(time.Second), correct, len(typedChars), mistakes, accuracy)
-	fmt.Printf("\033[%dB\r\n", linesNum)
-
-	// save result
-	result := db.Result{
-		WPM:       wpm,
-		Accuracy:  accuracy,
-		Correct:   correct,
-		Total:     len(typedChars),
-		Mistakes:  mistakes,
-		TimeTaken: timeTaken.Seconds(),
-	}
-	err = db.Save(result, charStats, dbFile)
-	if err != nil {
-		log.Fatalf("Failed to save result: %v", err)
+		next, err := promptNext(ctx, r, ev, tty.suspend, &lineLength, status)
+		if err != nil {
+			tty.fatalf("%v", err)
+		}
+		switch next {
+		case nextQuit:
+			return
+		case nextRepeat:
+			// zen mode always starts empty
+			if !t.zen {
+				t.lines = s.Text().Strings()
+			}
+		case nextNew:
+			t, err = newTest(cfg, src, lineLength)
+			if err != nil {
+				tty.fatalf("%v", err)
+			}
+		}
 	}
 }
 
-func getChar(index int) rune {
-	return []rune(wordsString)[index]
-}
-
-func printfColor(colorCode string, format string, a ...any) (n int, err error) {
-	return fmt.Fprintf(os.Stdout, colorCode+format+resetColor, a...)
+// fitLineLength limits the configured max line length to the terminal width,
+// a max line length of 0 uses the whole terminal width
+func fitLineLength(maxLineLength int, width int) int {
+	if maxLineLength <= 0 || maxLineLength > width {
+		return width
+	}
+	return maxLineLength
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+
+	"github.com/fr3dr/termtyper/render"
+	"github.com/fr3dr/termtyper/session"
+)
+
+type next int
+
+const (
+	nextQuit next = iota
+	nextRepeat
+	nextNew
+)
+
+const promptText = "r: repeat  n/tab: new words  q: quit"
+
+// promptNext asks what to do after a test is finished, status is shown in front of the prompt if set.
+// lineLength is kept up to date with resizes while waiting.
+func promptNext(ctx context.Context, r render.Renderer, ev session.Events, suspend func(), lineLength *int, status string) (next, error) {
+	text := promptText
+	if status != "" {
+		text = status + "  " + promptText
+	}
+	r.SetInfo(text, render.InfoStart)
+	if err := r.Flush(); err != nil {
+		return nextQuit, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.Clear()
+			return nextQuit, r.Flush()
+		case width := <-ev.Resize:
+			*lineLength = width
+		case <-ev.Continue:
+			r.Reset()
+			r.SetInfo(text, render.InfoStart)
+			if err := r.Flush(); err != nil {
+				return nextQuit, err
+			}
+		case key := <-ev.Keys:
+			switch {
+			case key.Type == session.KeyQuit || key.Type == session.KeyRune && key.Rune == 'q':
+				r.Clear()
+				return nextQuit, r.Flush()
+			case key.Type == session.KeyRune && key.Rune == 'r':
+				return nextRepeat, nil
+			case key.Type == session.KeySuspend:
+				// the continue event that follows redraws the prompt
+				suspend()
+			case key.Type == session.KeyRestart || key.Type == session.KeyTab || key.Type == session.KeyRune && key.Rune == 'n':
+				return nextNew, nil
+			}
+		}
+	}
+}
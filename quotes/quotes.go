@@ -0,0 +1,80 @@
+package quotes
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"unicode/utf8"
+)
+
+//go:embed quotes.json
+var quotesJSON []byte
+
+type Quote struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Source string `json:"source"`
+}
+
+// Lengths are the length buckets quotes are sorted into
+var Lengths = []string{"short", "medium", "long", "thicc"}
+
+// Length returns the length bucket of the quote
+func (q Quote) Length() string {
+	n := utf8.RuneCountInString(q.Text)
+	switch {
+	case n <= 100:
+		return "short"
+	case n <= 300:
+		return "medium"
+	case n <= 600:
+		return "long"
+	default:
+		return "thicc"
+	}
+}
+
+// All returns every embedded quote
+func All() []Quote {
+	var quotes []Quote
+	err := json.Unmarshal(quotesJSON, &quotes)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded quotes: %v", err))
+	}
+	return quotes
+}
+
+// Get returns the quote with the given id
+func Get(id int) (Quote, bool) {
+	for _, q := range All() {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return Quote{}, false
+}
+
+// Pick returns a quote by id, or a random quote of a length bucket.
+// "all" picks from every quote.
+func Pick(selection string) (Quote, error) {
+	if id, err := strconv.Atoi(selection); err == nil {
+		q, ok := Get(id)
+		if !ok {
+			return Quote{}, fmt.Errorf("no quote with id %d", id)
+		}
+		return q, nil
+	}
+
+	var matching []Quote
+	for _, q := range All() {
+		if selection == "all" || q.Length() == selection {
+			matching = append(matching, q)
+		}
+	}
+	if len(matching) == 0 {
+		return Quote{}, fmt.Errorf("unknown quote length %q, use one of %v, all or a quote id", selection, Lengths)
+	}
+	return matching[rand.IntN(len(matching))], nil
+}
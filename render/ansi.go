@@ -0,0 +1,137 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/fr3dr/termtyper/target"
+)
+
+// ANSI color escape codes
+var (
+	resetColor      = "\033[0m"
+	backgroundColor = "\033[90m"
+	infoStartColor  = "\033[2;92m"
+	infoColor       = "\033[92m"
+	infoDoneColor   = "\033[2;33m"
+	infoFailedColor = "\033[31m"
+	typedColor      = "\033[97m"
+	errorColor      = "\033[1;4;31m"
+	ghostColor      = "\033[30;46m"
+)
+
+var styleColors = map[Style]string{
+	Pending: backgroundColor,
+	Typed:   typedColor,
+	Error:   errorColor,
+	Ghost:   ghostColor,
+}
+
+var infoColors = map[InfoStyle]string{
+	InfoStart:   infoStartColor,
+	InfoRunning: infoColor,
+	InfoDone:    infoDoneColor,
+	InfoFailed:  infoFailedColor,
+}
+
+// ANSI draws to a terminal below the current cursor position using relative cursor movement.
+// Row 0 is the info line, the text starts at row 1.
+type ANSI struct {
+	w *bufio.Writer
+	// cursor is the escape code of the cursor shape, it is set again after every reset
+	cursor string
+
+	lines int
+
+	// where the terminal cursor is
+	row int
+
+	// where the cursor is put back to on flush
+	caretRow    int
+	caretColumn int
+}
+
+func NewANSI(w io.Writer, cursor string) *ANSI {
+	a := &ANSI{w: bufio.NewWriter(w), cursor: cursor}
+	fmt.Fprintf(a.w, "%s", cursor)
+	return a
+}
+
+func (a *ANSI) DrawText(text *target.Text) {
+	a.moveTo(0, 0)
+	fmt.Fprintf(a.w, "\033[J")
+	a.lines = 0
+	for row := range text.Lines() {
+		a.AppendLine(text.Line(row))
+	}
+}
+
+func (a *ANSI) AppendLine(line []rune) {
+	// print a new line below the last one, this scrolls the terminal if needed
+	a.moveTo(a.lines, 0)
+	fmt.Fprintf(a.w, "\r\n\033[2K%s", backgroundColor)
+	for _, r := range line {
+		fmt.Fprintf(a.w, "%c", display(r, Pending))
+	}
+	fmt.Fprintf(a.w, "%s", resetColor)
+	a.lines++
+	a.row = a.lines
+}
+
+func (a *ANSI) SetChar(row, column int, char rune, style Style) {
+	a.moveTo(row+1, column)
+	fmt.Fprintf(a.w, "%s%c%s", styleColors[style], display(char, style), resetColor)
+}
+
+func (a *ANSI) SetInfo(info string, style InfoStyle) {
+	a.moveTo(0, 0)
+	fmt.Fprintf(a.w, "\033[2K%s%s%s", infoColors[style], info, resetColor)
+}
+
+func (a *ANSI) MoveCaret(row, column int) {
+	a.caretRow = row + 1
+	a.caretColumn = column
+}
+
+func (a *ANSI) Clear() {
+	a.moveTo(0, 0)
+	fmt.Fprintf(a.w, "\033[J")
+	a.lines = 0
+	a.caretRow = 0
+	a.caretColumn = 0
+}
+
+func (a *ANSI) Reset() {
+	fmt.Fprintf(a.w, "%s\033[H\033[2J", a.cursor)
+	a.lines = 0
+	a.row = 0
+	a.caretRow = 0
+	a.caretColumn = 0
+}
+
+func (a *ANSI) Finish() {
+	a.moveTo(a.lines, 0)
+	fmt.Fprintf(a.w, "\r\n")
+	a.lines = 0
+	a.row = 0
+	a.caretRow = 0
+	a.caretColumn = 0
+}
+
+func (a *ANSI) Flush() error {
+	a.moveTo(a.caretRow, a.caretColumn)
+	return a.w.Flush()
+}
+
+// moveTo moves the terminal cursor to a row and column
+func (a *ANSI) moveTo(row, column int) {
+	switch {
+	case row < a.row:
+		fmt.Fprintf(a.w, "\033[%dA", a.row-row)
+	case row > a.row:
+		fmt.Fprintf(a.w, "\033[%dB", row-a.row)
+	}
+	a.row = row
+	fmt.Fprintf(a.w, "\033[%dG", column+1)
+}
@@ -0,0 +1,63 @@
+package render
+
+import "github.com/fr3dr/termtyper/target"
+
+// Style is how a character of the text is displayed
+type Style int
+
+const (
+	Pending Style = iota
+	Typed
+	Error
+	// Ghost is the character under the ghost caret
+	Ghost
+)
+
+// InfoStyle is how the info line is displayed
+type InfoStyle int
+
+const (
+	InfoStart InfoStyle = iota
+	InfoRunning
+	InfoDone
+	InfoFailed
+)
+
+// Renderer draws a typing test.
+// Rows and columns are relative to the start of the text, the info line is drawn above row 0.
+// Nothing has to be visible before Flush is called.
+type Renderer interface {
+	// DrawText clears the info line and draws the whole text as pending,
+	// the info line stays empty until SetInfo is called
+	DrawText(text *target.Text)
+	// AppendLine draws a line added to the end of the text
+	AppendLine(line []rune)
+	// SetChar redraws a single character of the text
+	SetChar(row, column int, char rune, style Style)
+	// SetInfo replaces the info line
+	SetInfo(info string, style InfoStyle)
+	// MoveCaret moves the caret to where the next character is typed
+	MoveCaret(row, column int)
+	// Clear removes the info line and the text
+	Clear()
+	// Reset clears the whole screen and starts drawing again at the top,
+	// used when the layout of the terminal can no longer be trusted
+	Reset()
+	// Finish leaves the drawn text behind so following output goes below it
+	Finish()
+	// Flush makes everything drawn so far visible
+	Flush() error
+}
+
+// display returns the rune that is shown for a character in a style
+func display(char rune, style Style) rune {
+	switch {
+	case char == '\n':
+		return '↵'
+	case char == '\t':
+		return '→'
+	case style == Error && char == ' ':
+		return '_'
+	}
+	return char
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/fr3dr/termtyper/target"
+)
+
+// Cell is a single column of the screen.
+// The second column of a wide character has a zero Rune.
+type Cell struct {
+	Rune  rune
+	Style Style
+}
+
+// Screen is an in-memory renderer that keeps a grid of what a terminal would show
+type Screen struct {
+	Info      string
+	InfoStyle InfoStyle
+	Rows      [][]Cell
+
+	CaretRow    int
+	CaretColumn int
+
+	// Finished is set once Finish is called
+	Finished bool
+}
+
+func NewScreen() *Screen {
+	return &Screen{}
+}
+
+func (s *Screen) DrawText(text *target.Text) {
+	s.Info = ""
+	s.InfoStyle = InfoStart
+	s.Rows = nil
+	for row := range text.Lines() {
+		s.AppendLine(text.Line(row))
+	}
+}
+
+func (s *Screen) AppendLine(line []rune) {
+	var cells []Cell
+	for _, r := range line {
+		cells = append(cells, Cell{Rune: display(r, Pending), Style: Pending})
+		for range target.RuneWidth(r) - 1 {
+			cells = append(cells, Cell{Style: Pending})
+		}
+	}
+	s.Rows = append(s.Rows, cells)
+}
+
+func (s *Screen) SetChar(row, column int, char rune, style Style) {
+	for len(s.Rows) <= row {
+		s.Rows = append(s.Rows, nil)
+	}
+	for len(s.Rows[row]) <= column {
+		s.Rows[row] = append(s.Rows[row], Cell{Rune: ' '})
+	}
+	s.Rows[row][column] = Cell{Rune: display(char, style), Style: style}
+	if target.RuneWidth(char) > 1 && column+1 < len(s.Rows[row]) {
+		s.Rows[row][column+1] = Cell{Style: style}
+	}
+}
+
+func (s *Screen) SetInfo(info string, style InfoStyle) {
+	s.Info = info
+	s.InfoStyle = style
+}
+
+func (s *Screen) MoveCaret(row, column int) {
+	s.CaretRow = row
+	s.CaretColumn = column
+}
+
+func (s *Screen) Clear() {
+	s.Info = ""
+	s.Rows = nil
+	s.CaretRow = 0
+	s.CaretColumn = 0
+}
+
+func (s *Screen) Reset() {
+	s.Clear()
+}
+
+func (s *Screen) Finish() {
+	s.Finished = true
+}
+
+func (s *Screen) Flush() error {
+	return nil
+}
+
+// Line returns the text shown in a row
+func (s *Screen) Line(row int) string {
+	var b strings.Builder
+	for _, c := range s.Rows[row] {
+		if c.Rune != 0 {
+			b.WriteRune(c.Rune)
+		}
+	}
+	return b.String()
+}
+
+// Styles returns the style of every cell in a row as a string,
+// using '.' for pending, 't' for typed, 'e' for errors and 'g' for the ghost
+func (s *Screen) Styles(row int) string {
+	var b strings.Builder
+	for _, c := range s.Rows[row] {
+		b.WriteByte(".teg"[c.Style])
+	}
+	return b.String()
+}
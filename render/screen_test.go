@@ -0,0 +1,189 @@
+package render_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fr3dr/termtyper/render"
+	"github.com/fr3dr/termtyper/session"
+)
+
+func typeKeys(s *session.Session, screen *render.Screen, typed string) {
+	now := time.Unix(0, 0)
+	for _, r := range typed {
+		now = now.Add(100 * time.Millisecond)
+		key := session.Key{Type: session.KeyRune, Rune: r}
+		if r == '\b' {
+			key = session.Key{Type: session.KeyBackspace}
+		}
+		// like Run does it
+		if update := s.Handle(key, now); update.Redraw {
+			s.Redraw(screen, now)
+		} else {
+			s.Draw(screen, update)
+		}
+	}
+}
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		typed string
+
+		shown  []string
+		styles []string
+		row    int
+		column int
+	}{
+		{
+			name:   "pending text",
+			lines:  []string{"ab cd"},
+			shown:  []string{"ab cd"},
+			styles: []string{"....."},
+		},
+		{
+			name:   "mistakes",
+			lines:  []string{"ab cd"},
+			typed:  "ax",
+			shown:  []string{"ab cd"},
+			styles: []string{"te..."},
+			column: 2,
+		},
+		{
+			name:   "mistyped space",
+			lines:  []string{"ab cd"},
+			typed:  "abx",
+			shown:  []string{"ab_cd"},
+			styles: []string{"tte.."},
+			column: 3,
+		},
+		{
+			name:   "backspace",
+			lines:  []string{"ab cd"},
+			typed:  "ax\b",
+			shown:  []string{"ab cd"},
+			styles: []string{"t...."},
+			column: 1,
+		},
+		{
+			name:   "caret after a wrap",
+			lines:  []string{"hello ", "world"},
+			typed:  "hello ",
+			shown:  []string{"hello ", "world"},
+			styles: []string{"tttttt", "....."},
+			row:    1,
+		},
+		{
+			name:   "wide characters",
+			lines:  []string{"日本 ab"},
+			typed:  "日x",
+			shown:  []string{"日本 ab"},
+			styles: []string{"ttee..."},
+			column: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := session.New(session.Config{}, tt.lines)
+			screen := render.NewScreen()
+			s.Redraw(screen, time.Unix(0, 0))
+			typeKeys(s, screen, tt.typed)
+
+			for row := range tt.shown {
+				if got := screen.Line(row); got != tt.shown[row] {
+					t.Errorf("line %d = %q, want %q", row, got, tt.shown[row])
+				}
+				if got := screen.Styles(row); got != tt.styles[row] {
+					t.Errorf("styles %d = %q, want %q", row, got, tt.styles[row])
+				}
+			}
+			if screen.CaretRow != tt.row || screen.CaretColumn != tt.column {
+				t.Errorf("caret = %d,%d, want %d,%d", screen.CaretRow, screen.CaretColumn, tt.row, tt.column)
+			}
+
+			// drawing the changes of every key ends up the same as drawing everything again
+			redrawn := render.NewScreen()
+			s.Redraw(redrawn, time.Unix(0, 0))
+			if !reflect.DeepEqual(screen.Rows, redrawn.Rows) {
+				t.Errorf("drawn changes differ from a redraw:\n%v\n%v", screen.Rows, redrawn.Rows)
+			}
+		})
+	}
+}
+
+func TestScreenRun(t *testing.T) {
+	keys := make(chan session.Key, 3)
+	for _, r := range "ab" {
+		keys <- session.Key{Type: session.KeyRune, Rune: r}
+	}
+	s := session.New(session.Config{}, []string{"ab"})
+	screen := render.NewScreen()
+
+	exit, err := s.Run(context.Background(), screen, session.Events{Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exit != session.ExitFinished {
+		t.Fatalf("exit = %v, want finished", exit)
+	}
+	if !screen.Finished || screen.InfoStyle != render.InfoDone {
+		t.Errorf("finished = %v, info style = %v, want the result", screen.Finished, screen.InfoStyle)
+	}
+	if got := screen.Styles(0); got != "tt" {
+		t.Errorf("styles = %q, want %q", got, "tt")
+	}
+}
+
+func TestScreenBlind(t *testing.T) {
+	s := session.New(session.Config{Blind: true}, []string{"abc d"})
+	screen := render.NewScreen()
+	s.Redraw(screen, time.Unix(0, 0))
+
+	// mistakes look typed until the end
+	typeKeys(s, screen, "ax")
+	if line, styles := screen.Line(0), screen.Styles(0); line != "abc d" || styles != "tt..." {
+		t.Errorf("while typing = %q %q, want %q %q", line, styles, "abc d", "tt...")
+	}
+
+	// the result shows what was typed in place of mistakes
+	typeKeys(s, screen, "cyd")
+	s.Redraw(screen, time.Unix(1, 0))
+	if line, styles := screen.Line(0), screen.Styles(0); line != "axcyd" || styles != "tetet" {
+		t.Errorf("result = %q %q, want %q %q", line, styles, "axcyd", "tetet")
+	}
+}
+
+func TestScreenMemory(t *testing.T) {
+	s := session.New(session.Config{Memorize: time.Second, LineLength: 40}, []string{"the cat sat"})
+	screen := render.NewScreen()
+	s.Redraw(screen, time.Unix(0, 0))
+
+	// the first key hides the text, then only what is typed is shown
+	typeKeys(s, screen, " teh ca")
+	if line, styles := screen.Line(0), screen.Styles(0); line != "teh ca     " || styles != "tttttt....." {
+		t.Errorf("while recalling = %q %q, want %q %q", line, styles, "teh ca     ", "tttttt.....")
+	}
+
+	// wrong words are followed by what was typed instead
+	typeKeys(s, screen, "t sit")
+	s.Redraw(screen, time.Unix(1, 0))
+	want, wantStyles := "the teh cat sat sit", "eee....ttttteee...."
+	if line, styles := screen.Line(0), screen.Styles(0); line != want || styles != wantStyles {
+		t.Errorf("result = %q %q, want %q %q", line, styles, want, wantStyles)
+	}
+}
+
+func TestScreenMemoryWide(t *testing.T) {
+	s := session.New(session.Config{Memorize: time.Second, LineLength: 40}, []string{"日本 ab"})
+	screen := render.NewScreen()
+	s.Redraw(screen, time.Unix(0, 0))
+
+	// the hidden text is as wide as the text
+	typeKeys(s, screen, " 日")
+	if line, styles := screen.Line(0), screen.Styles(0); line != "日     " || styles != "tt....." {
+		t.Errorf("while recalling = %q %q, want %q %q", line, styles, "日     ", "tt.....")
+	}
+}
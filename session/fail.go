@@ -0,0 +1,38 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// wpmGrace is how long typing can be slower than MinWPM at the start
+const wpmGrace = 5 * time.Second
+
+// accuracyGrace is how many characters are typed before MinAccuracy is checked,
+// so a mistake on the first character does not fail right away
+const accuracyGrace = 10
+
+// checkFail ends the session as failed when one of the fail conditions is met
+func (s *Session) checkFail(now time.Time) {
+	if !s.started || s.finished || s.cfg.Zen {
+		return
+	}
+	switch {
+	case s.cfg.SuddenDeath && s.mistakes > 0:
+		s.fail(now, "mistake")
+	case s.cfg.MinAccuracy > 0 && s.correct+s.mistakes >= accuracyGrace && s.Accuracy() < s.cfg.MinAccuracy:
+		s.fail(now, fmt.Sprintf("accuracy below %g%%", s.cfg.MinAccuracy))
+	case s.cfg.MinWPM > 0 && s.Elapsed(now) >= wpmGrace && s.WPM(now) < s.cfg.MinWPM:
+		s.fail(now, fmt.Sprintf("speed below %gwpm", s.cfg.MinWPM))
+	}
+}
+
+func (s *Session) fail(now time.Time, reason string) {
+	s.finish(now)
+	s.failReason = reason
+}
+
+// Failed returns why the session failed, or an empty string if it did not
+func (s *Session) Failed() string {
+	return s.failReason
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fr3dr/termtyper/render"
+)
+
+// Ghost is a second caret the user races against
+type Ghost interface {
+	// Index returns how many characters the ghost has typed after elapsed typing time
+	Index(elapsed time.Duration) int
+	// Time returns when the ghost has typed index characters
+	Time(index int) time.Duration
+}
+
+// Pacer is a ghost typing at a constant speed in wpm
+type Pacer float64
+
+func (p Pacer) Index(elapsed time.Duration) int {
+	return int(float64(p) * 5 * elapsed.Minutes())
+}
+
+func (p Pacer) Time(index int) time.Duration {
+	return time.Duration(float64(index) / 5 / float64(p) * float64(time.Minute))
+}
+
+// Replay is a ghost repeating a previous run, it has the time every character was typed at
+type Replay []time.Duration
+
+func (r Replay) Index(elapsed time.Duration) int {
+	return sort.Search(len(r), func(i int) bool {
+		return r[i] > elapsed
+	})
+}
+
+func (r Replay) Time(index int) time.Duration {
+	if index <= 0 || len(r) == 0 {
+		return 0
+	}
+	return r[min(index, len(r))-1]
+}
+
+// moveGhost moves the ghost caret to where the ghost is after elapsed typing time
+func (s *Session) moveGhost(elapsed time.Duration, update *Update) {
+	if s.Ghost == nil || !s.started {
+		return
+	}
+	index := min(s.Ghost.Index(elapsed), s.text.Len()-1)
+	if index == s.ghostIndex {
+		return
+	}
+	s.change(update, s.ghostIndex, s.styleAt(s.ghostIndex))
+	s.ghostIndex = index
+	s.change(update, s.ghostIndex, render.Ghost)
+}
+
+// styleAt returns how the character at index is displayed without the ghost
+func (s *Session) styleAt(index int) render.Style {
+	switch {
+	case index >= s.index:
+		return render.Pending
+	case s.typed[index] == s.text.At(index):
+		return render.Typed
+	default:
+		return render.Error
+	}
+}
+
+// ghostInfo tells how far ahead or behind the ghost the user finished
+func (s *Session) ghostInfo() string {
+	diff := s.Elapsed(s.endTime) - s.Ghost.Time(s.index)
+	switch {
+	case diff < 0:
+		return fmt.Sprintf("  %s ahead of ghost", (-diff).Round(10*time.Millisecond))
+	case diff > 0:
+		return fmt.Sprintf("  %s behind ghost", diff.Round(10*time.Millisecond))
+	default:
+		return "  tied with ghost"
+	}
+}
@@ -0,0 +1,37 @@
+package session
+
+import "github.com/fr3dr/termtyper/render"
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// skippable reports whether the character at index is indentation that is typed automatically
+func (s *Session) skippable(index int) bool {
+	if !s.cfg.Code || s.cfg.TypeIndent {
+		return false
+	}
+	for i := index; i >= 0; i-- {
+		switch s.text.At(i) {
+		case ' ', '\t':
+			continue
+		case '\n':
+			return i != index
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// skipIndent moves the cursor past indentation at the start of a line
+func (s *Session) skipIndent(update *Update) {
+	for s.index < s.text.Len() && s.skippable(s.index) {
+		s.typed = append(s.typed, s.text.At(s.index))
+		// skipped characters are typed together with the one before them
+		s.times = append(s.times, s.lastTime())
+		s.change(update, s.index, render.Typed)
+		s.index++
+		s.skipped++
+	}
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/fr3dr/termtyper/render"
+)
+
+// Events are the channels a running session receives from.
+// Ticks update the info line and end timed mode, resizes carry the new line length
+// and Continue redraws everything after the program was stopped from outside.
+type Events struct {
+	Keys     <-chan Key
+	Ticks    <-chan time.Time
+	Resize   <-chan int
+	Continue <-chan struct{}
+}
+
+// Exit is why Run returned
+type Exit int
+
+const (
+	ExitFinished Exit = iota
+	ExitQuit
+	ExitRestart
+)
+
+// Run draws the session and drives it with events until it finishes, is quit or ctx is done.
+// Run is the only place that touches the session and the renderer while it is running,
+// so events can be sent from any goroutine.
+func (s *Session) Run(ctx context.Context, r render.Renderer, events Events) (Exit, error) {
+	s.Redraw(r, time.Now())
+	if err := r.Flush(); err != nil {
+		return ExitQuit, err
+	}
+
+	for {
+		var update Update
+		// events are timed when they are handled, a tick or key may have waited in its channel
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			update.Quit = true
+		case key, ok := <-events.Keys:
+			if !ok {
+				key = Key{Type: KeyQuit}
+			}
+			now = time.Now()
+			update = s.Handle(key, now)
+			if update.Redraw {
+				s.Redraw(r, now)
+			} else {
+				s.Draw(r, update)
+			}
+		case <-events.Ticks:
+			now = time.Now()
+			update = s.Tick(now)
+			switch {
+			case update.Redraw:
+				s.Redraw(r, now)
+			case len(update.Changes) > 0:
+				s.Draw(r, update)
+			}
+			if (s.started || s.memorizing) && !s.paused && !update.Finished {
+				r.SetInfo(s.Info(now), s.InfoStyle())
+			}
+		case width := <-events.Resize:
+			now = time.Now()
+			s.Reflow(width)
+			r.Reset()
+			s.Redraw(r, now)
+		case <-events.Continue:
+			now = time.Now()
+			r.Reset()
+			s.Redraw(r, now)
+		}
+
+		switch {
+		case update.Quit:
+			// clear everything
+			r.Clear()
+			return ExitQuit, r.Flush()
+		case update.Restart:
+			r.Clear()
+			return ExitRestart, r.Flush()
+		case update.Suspend:
+			// the timer does not run while the program is stopped
+			wasPaused := s.paused
+			s.Pause(now)
+			if err := r.Flush(); err != nil {
+				return ExitQuit, err
+			}
+			if s.Suspend != nil {
+				s.Suspend()
+			}
+			if !wasPaused {
+				s.Resume(time.Now())
+			}
+			// the continue event that follows redraws everything
+		case update.Finished:
+			// reveal the mistakes
+			if s.cfg.Blind || s.cfg.Memorize > 0 {
+				s.Redraw(r, now)
+			}
+			r.SetInfo(s.Info(now), s.InfoStyle())
+			r.Finish()
+			return ExitFinished, r.Flush()
+		}
+
+		if err := r.Flush(); err != nil {
+			return ExitQuit, err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fr3dr/termtyper/render"
+)
+
+// infoRecorder keeps every info line that was drawn
+type infoRecorder struct {
+	*render.Screen
+	infos []string
+}
+
+func (r *infoRecorder) SetInfo(info string, style render.InfoStyle) {
+	r.infos = append(r.infos, info)
+	r.Screen.SetInfo(info, style)
+}
+
+// TestRunConcurrent sends keys, ticks and resizes from their own goroutines while the session runs,
+// run it with -race
+func TestRunConcurrent(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+	keys := make(chan Key)
+	ticks := make(chan time.Time, 10)
+	resize := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for _, r := range text {
+			select {
+			case keys <- Key{Type: KeyRune, Rune: r}:
+			case <-ctx.Done():
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			// ticks that waited in the channel are older than the keys handled before them
+			select {
+			case ticks <- time.Now().Add(-time.Second):
+			case <-ctx.Done():
+				return
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, width := range []int{20, 10, 30} {
+			select {
+			case resize <- width:
+			case <-ctx.Done():
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	s := New(Config{LineLength: 20}, []string{text})
+	s.Reflow(20)
+	r := &infoRecorder{Screen: render.NewScreen()}
+	exit, err := s.Run(ctx, r, Events{Keys: keys, Ticks: ticks, Resize: resize})
+	cancel()
+	wg.Wait()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exit != ExitFinished {
+		t.Fatalf("exit = %v, want finished", exit)
+	}
+	if s.Correct() != len(text) || s.Mistakes() != 0 {
+		t.Errorf("correct = %d, mistakes = %d, want %d and 0", s.Correct(), s.Mistakes(), len(text))
+	}
+	for _, info := range r.infos {
+		if strings.Contains(info, "-") {
+			t.Errorf("info line %q has a negative value", info)
+		}
+	}
+}
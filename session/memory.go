@@ -0,0 +1,124 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fr3dr/termtyper/render"
+	"github.com/fr3dr/termtyper/target"
+)
+
+// in memory mode the text is shown for cfg.Memorize or until a key is pressed,
+// then it is hidden and only what is typed is shown until the session is finished
+
+// memorize hides the text once the time to memorize it is up
+func (s *Session) memorize(now time.Time, update *Update) {
+	if !s.memorizing {
+		return
+	}
+	if s.hideAt.IsZero() {
+		s.hideAt = now.Add(s.cfg.Memorize)
+	}
+	if !now.Before(s.hideAt) {
+		s.memorizing = false
+		update.Redraw = true
+	}
+}
+
+// recalling reports whether the text is hidden while it is typed from memory
+func (s *Session) recalling() bool {
+	return s.cfg.Memorize > 0 && !s.memorizing && !s.finished
+}
+
+// charShown returns the character displayed at index in a style,
+// while recalling that is what was typed instead of the text
+func (s *Session) charShown(index int, style render.Style) rune {
+	switch {
+	case !s.recalling():
+		return s.text.At(index)
+	case style == render.Pending:
+		return ' '
+	default:
+		return s.typed[index]
+	}
+}
+
+// blank returns a text of spaces with lines as wide as the lines of the text
+func (s *Session) blank() *target.Text {
+	lines := make([]string, s.text.Lines())
+	for row := range lines {
+		lines[row] = strings.Repeat(" ", target.Width(string(s.text.Line(row))))
+	}
+	return target.New(lines)
+}
+
+// wordStyle returns the style of the character at index in the result of memory mode,
+// a word is shown as a mistake as a whole if any of its characters was not recalled
+func (s *Session) wordStyle(index int) render.Style {
+	if isSpace(s.text.At(index)) {
+		return s.styleAt(index)
+	}
+	start, end := index, index
+	for start > 0 && !isSpace(s.text.At(start-1)) {
+		start--
+	}
+	for end < s.text.Len() && !isSpace(s.text.At(end)) {
+		end++
+	}
+	for i := start; i < end; i++ {
+		if s.styleAt(i) != render.Typed {
+			return render.Error
+		}
+	}
+	return render.Typed
+}
+
+// recalledText returns the result of memory mode and the style of every rune in it,
+// every word that was not recalled is followed by what was typed in its place
+func (s *Session) recalledText() (*target.Text, []render.Style) {
+	var runes []rune
+	var styles []render.Style
+	for i := 0; i < s.text.Len(); {
+		if isSpace(s.text.At(i)) {
+			runes = append(runes, s.text.At(i))
+			styles = append(styles, s.styleAt(i))
+			i++
+			continue
+		}
+		end := i
+		for end < s.text.Len() && !isSpace(s.text.At(end)) {
+			end++
+		}
+		style := s.wordStyle(i)
+		for j := i; j < end; j++ {
+			runes = append(runes, s.text.At(j))
+			styles = append(styles, style)
+		}
+		if typed := s.typed[min(i, s.index):min(end, s.index)]; style == render.Error && len(typed) > 0 {
+			runes = append(append(runes, ' '), typed...)
+			for range len(typed) + 1 {
+				styles = append(styles, render.Pending)
+			}
+		}
+		i = end
+	}
+	text := target.New([]string{string(runes)})
+	text.Reflow(s.cfg.LineLength)
+	return text, styles
+}
+
+// recalledInfo counts the words that were typed without mistakes
+func (s *Session) recalledInfo() string {
+	words, recalled := 0, 0
+	for i := 0; i < s.text.Len(); i++ {
+		if isSpace(s.text.At(i)) || i > 0 && !isSpace(s.text.At(i-1)) {
+			continue
+		}
+		words++
+		if s.wordStyle(i) == render.Typed {
+			recalled++
+		}
+	}
+	return fmt.Sprintf("  %d/%d words recalled", recalled, words)
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"time"
+
+	"github.com/fr3dr/termtyper/db"
+)
+
+// recordNGrams times the bigram and trigram ending at the cursor when char is typed.
+// Only transitions from correctly typed characters are counted,
+// a wrong char counts as an error of the n-grams it should have completed.
+func (s *Session) recordNGrams(char rune, elapsed time.Duration) {
+	for n := 2; n <= 3; n++ {
+		start := s.index - n + 1
+		if start < 0 || !s.typedCleanly(start, s.index) {
+			continue
+		}
+		nGram := string(s.text.Runes(start, s.index+1))
+		stat := s.nGramStats[nGram]
+		if char == s.text.At(s.index) {
+			stat.Count++
+			stat.Time += (elapsed - s.times[start]).Seconds()
+		} else {
+			stat.Errors++
+		}
+		s.nGramStats[nGram] = stat
+	}
+}
+
+// typedCleanly reports whether every character from start up to end was typed correctly by hand
+func (s *Session) typedCleanly(start, end int) bool {
+	for i := start; i < end; i++ {
+		if s.skippable(i) || s.typed[i] != s.text.At(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// NGramStats returns the bigrams and trigrams typed in the session
+func (s *Session) NGramStats() map[string]db.NGramStat {
+	return s.nGramStats
+}
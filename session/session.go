@@ -0,0 +1,606 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/render"
+	"github.com/fr3dr/termtyper/target"
+)
+
+type KeyType int
+
+const (
+	KeyRune KeyType = iota
+	KeyBackspace
+	KeyDeleteWord
+	KeyQuit
+	KeySuspend
+	KeyPause
+	KeyRestart
+	// KeyTab restarts the session unless tabs have to be typed
+	KeyTab
+	// KeyFinish ends a session in zen mode
+	KeyFinish
+)
+
+// Key is a single input event the session reacts to
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// Change describes a character that has to be redrawn
+type Change struct {
+	Index  int
+	Row    int
+	Column int
+	Char   rune
+	Style  render.Style
+}
+
+// Update is everything that changed after handling a key or tick
+type Update struct {
+	Changes  []Change
+	NewLines []string
+	Finished bool
+	Quit     bool
+	Suspend  bool
+	Restart  bool
+	// Redraw is set when everything has to be drawn again
+	Redraw bool
+}
+
+type Config struct {
+	NoBackspace bool
+	CorrectOnly bool
+	TimedMode   time.Duration
+	// LineLength is the width of lines generated in timed mode
+	LineLength int
+	// Attribution is shown after the result, like the source of a quote
+	Attribution string
+	// Code keeps the line breaks of the text which have to be typed with enter,
+	// indentation at the start of lines is skipped unless TypeIndent is set
+	Code       bool
+	TypeIndent bool
+	// Zen starts without a text, whatever is typed becomes the text until KeyFinish
+	Zen bool
+	// the session fails on the first mistake, when accuracy or wpm drop below a minimum
+	SuddenDeath bool
+	MinAccuracy float64
+	MinWPM      float64
+	// Blind hides mistakes until the session is finished
+	Blind bool
+	// Memorize is how long the text is shown before it has to be typed from memory
+	Memorize time.Duration
+}
+
+type Session struct {
+	cfg Config
+
+	// NextLine generates a new line of at most width columns in timed mode
+	NextLine func(width int) string
+
+	// Suspend stops the program after ctrl-z and returns once it is continued
+	Suspend func()
+
+	// Ghost is shown as a second caret if set
+	Ghost      Ghost
+	ghostIndex int
+
+	text *target.Text
+
+	index       int
+	correct     int
+	mistakes    int
+	mistakeMade bool
+	typed       []rune
+	// skipped is the number of typed characters that were skipped indentation
+	skipped   int
+	charStats map[rune]db.CharStat
+	// times has when every character before the cursor was typed
+	times      []time.Duration
+	nGramStats map[string]db.NGramStat
+
+	started  bool
+	finished bool
+	// failReason is set when a fail condition ended the session
+	failReason string
+
+	// memorizing is set while the text is shown in memory mode
+	memorizing bool
+	hideAt     time.Time
+	startTime  time.Time
+	endTime    time.Time
+
+	// time spent paused is not counted as typing time
+	paused      bool
+	pausedAt    time.Time
+	pausedTotal time.Duration
+	pauses      int
+}
+
+func New(cfg Config, lines []string) *Session {
+	s := &Session{
+		cfg:        cfg,
+		text:       target.New(lines),
+		charStats:  make(map[rune]db.CharStat, 95),
+		nGramStats: make(map[string]db.NGramStat),
+		memorizing: cfg.Memorize > 0,
+	}
+	s.skipIndent(&Update{})
+	return s
+}
+
+// Handle applies a key to the session at time now
+func (s *Session) Handle(key Key, now time.Time) Update {
+	var update Update
+	switch key.Type {
+	case KeyQuit:
+		update.Quit = true
+		return update
+	case KeySuspend:
+		update.Suspend = true
+		return update
+	case KeyRestart:
+		update.Restart = true
+		return update
+	case KeyTab:
+		if !s.cfg.Code || !s.cfg.TypeIndent {
+			update.Restart = true
+			return update
+		}
+		key = Key{Type: KeyRune, Rune: '\t'}
+	case KeyFinish:
+		if s.cfg.Zen && s.started && !s.finished {
+			s.finish(now)
+		}
+		update.Finished = s.finished
+		return update
+	case KeyPause:
+		if s.started && !s.finished {
+			if s.paused {
+				s.Resume(now)
+			} else {
+				s.Pause(now)
+			}
+			update.Redraw = true
+		}
+		return update
+	}
+	if s.finished {
+		update.Finished = true
+		return update
+	}
+	// a key hides the text early in memory mode
+	if s.memorizing {
+		s.memorizing = false
+		update.Redraw = true
+		return update
+	}
+	// nothing can be typed while paused
+	if s.paused {
+		return update
+	}
+
+	switch {
+	case key.Type == KeyDeleteWord && !s.cfg.NoBackspace && !s.cfg.CorrectOnly:
+		s.deleteWord(&update)
+	case key.Type == KeyBackspace && !s.cfg.NoBackspace && !s.cfg.CorrectOnly:
+		s.backspace(&update)
+	case key.Type == KeyRune && s.cfg.Zen:
+		s.typeFree(key.Rune, now, &update)
+	case key.Type == KeyRune && (key.Rune != '\n' || s.cfg.Code):
+		s.typeRune(key.Rune, now, &update)
+	}
+
+	s.checkFail(now)
+
+	// end game
+	if (!s.cfg.Zen && s.index == s.text.Len()) || s.timeUp(now) {
+		s.finish(now)
+	}
+	update.Finished = s.finished
+	return update
+}
+
+// Tick ends the session once the time in timed mode runs out
+func (s *Session) Tick(now time.Time) Update {
+	var update Update
+	if !s.finished && s.timeUp(now) {
+		s.finish(now)
+	}
+	if !s.paused {
+		s.checkFail(now)
+	}
+	s.memorize(now, &update)
+	if !s.finished && !s.paused {
+		s.moveGhost(s.Elapsed(now), &update)
+	}
+	update.Finished = s.finished
+	return update
+}
+
+func (s *Session) typeRune(char rune, now time.Time, update *Update) {
+	if !s.started {
+		s.startTime = now
+		s.started = true
+	}
+
+	expected := s.text.At(s.index)
+	charStat := s.charStats[expected]
+	// the time to correct a mistake is not the time of a transition
+	if !s.mistakeMade {
+		s.recordNGrams(char, s.Elapsed(now))
+	}
+
+	if !s.cfg.CorrectOnly || !s.mistakeMade {
+		s.typed = append(s.typed, char)
+	}
+
+	if expected == char {
+		if s.cfg.CorrectOnly && s.mistakeMade {
+			s.mistakeMade = false
+			s.change(update, s.index, render.Error)
+		} else {
+			s.correct++
+			charStat.Correct++
+			s.change(update, s.index, render.Typed)
+		}
+	} else {
+		s.mistakes++
+		charStat.Incorrect++
+		if s.cfg.CorrectOnly {
+			s.mistakeMade = true
+			s.typed[s.index] = char
+		} else {
+			s.change(update, s.index, render.Error)
+		}
+	}
+
+	s.charStats[expected] = charStat
+
+	if !s.cfg.CorrectOnly || !s.mistakeMade {
+		s.times = append(s.times, s.Elapsed(now))
+		s.index++
+		s.skipIndent(update)
+	}
+
+	s.fillLines(update)
+}
+
+// fillLines adds new lines in timed mode until the cursor is no longer on the last line
+func (s *Session) fillLines(update *Update) {
+	if s.cfg.TimedMode <= 0 || s.NextLine == nil {
+		return
+	}
+	for row, _ := s.text.Position(s.index); row == s.text.Lines()-1; row, _ = s.text.Position(s.index) {
+		line := s.NextLine(s.cfg.LineLength)
+		if line == "" {
+			return
+		}
+		s.text.AppendLine(line)
+		update.NewLines = append(update.NewLines, line)
+	}
+}
+
+func (s *Session) backspace(update *Update) {
+	// skipped indentation is removed together with the character before it
+	for s.index > 0 && s.skippable(s.index-1) {
+		s.untype(update)
+	}
+	// dont backspace out of bounds
+	if s.index > 0 {
+		s.untype(update)
+	}
+	s.skipIndent(update)
+}
+
+func (s *Session) deleteWord(update *Update) {
+	nonWhitespaceFound := false
+	for s.index > 0 && (!isSpace(s.text.At(s.index-1)) || !nonWhitespaceFound) {
+		if !isSpace(s.text.At(s.index - 1)) {
+			nonWhitespaceFound = true
+		}
+		s.untype(update)
+	}
+	s.skipIndent(update)
+}
+
+// untype removes the last typed character
+func (s *Session) untype(update *Update) {
+	if s.cfg.Zen {
+		s.untypeFree(update)
+		return
+	}
+	s.index--
+	if s.skippable(s.index) {
+		s.skipped--
+	} else if s.typed[s.index] == s.text.At(s.index) {
+		s.correct--
+	}
+	s.typed = s.typed[:s.index]
+	s.times = s.times[:s.index]
+	s.change(update, s.index, render.Pending)
+}
+
+func (s *Session) change(update *Update, index int, style render.Style) {
+	row, column := s.text.Position(index)
+	update.Changes = append(update.Changes, Change{
+		Index:  index,
+		Row:    row,
+		Column: column,
+		Char:   s.charShown(index, style),
+		Style:  s.shown(style),
+	})
+}
+
+// shown returns the style a character is displayed with,
+// mistakes look typed in blind mode and while recalling the text in memory mode
+func (s *Session) shown(style render.Style) render.Style {
+	if (s.cfg.Blind || s.recalling()) && !s.finished && style == render.Error {
+		return render.Typed
+	}
+	return style
+}
+
+// revealed returns the character drawn at index in a style,
+// the result of blind mode shows what was typed in place of mistakes if it is as wide
+func (s *Session) revealed(index int, style render.Style) rune {
+	expected := s.text.At(index)
+	if s.cfg.Blind && s.finished && style == render.Error && target.RuneWidth(s.typed[index]) == target.RuneWidth(expected) {
+		return s.typed[index]
+	}
+	return expected
+}
+
+func (s *Session) timeUp(now time.Time) bool {
+	return s.started && s.cfg.TimedMode > 0 && s.Elapsed(now) >= s.cfg.TimedMode
+}
+
+func (s *Session) finish(now time.Time) {
+	s.finished = true
+	s.endTime = now
+}
+
+func (s *Session) Started() bool      { return s.started }
+func (s *Session) Paused() bool       { return s.paused }
+func (s *Session) Finished() bool     { return s.finished }
+func (s *Session) Index() int         { return s.index }
+func (s *Session) Correct() int       { return s.correct }
+func (s *Session) Mistakes() int      { return s.mistakes }
+func (s *Session) Typed() int         { return len(s.typed) }
+func (s *Session) Text() *target.Text { return s.text }
+func (s *Session) LineLength() int    { return s.cfg.LineLength }
+
+// Times returns the typing time at which every character before the cursor was typed
+func (s *Session) Times() []time.Duration {
+	return s.times
+}
+
+func (s *Session) lastTime() time.Duration {
+	if len(s.times) == 0 {
+		return 0
+	}
+	return s.times[len(s.times)-1]
+}
+
+// Position returns the row and column of the cursor
+func (s *Session) Position() (int, int) {
+	return s.text.Position(s.index)
+}
+
+func (s *Session) CharStats() map[rune]db.CharStat {
+	return s.charStats
+}
+
+// Elapsed returns the time spent typing so far
+func (s *Session) Elapsed(now time.Time) time.Duration {
+	if !s.started {
+		return 0
+	}
+	if s.finished {
+		now = s.endTime
+	}
+	if s.paused {
+		now = s.pausedAt
+	}
+	return now.Sub(s.startTime) - s.pausedTotal
+}
+
+// Pause stops the timer until Resume is called
+func (s *Session) Pause(now time.Time) {
+	if s.paused || s.finished {
+		return
+	}
+	s.paused = true
+	s.pausedAt = now
+	if s.started {
+		s.pauses++
+	}
+}
+
+// Resume continues the timer after Pause
+func (s *Session) Resume(now time.Time) {
+	if !s.paused {
+		return
+	}
+	s.paused = false
+	if s.started {
+		s.pausedTotal += now.Sub(s.pausedAt)
+	}
+}
+
+// WPM is calculated from correct characters, or from every character typed in zen mode
+func (s *Session) WPM(now time.Time) float64 {
+	chars := s.correct
+	if s.cfg.Zen {
+		chars += s.mistakes
+	}
+	// a test failed on the first key has taken no time
+	elapsed := s.Elapsed(now)
+	if elapsed == 0 {
+		return 0
+	}
+	return float64(chars) / 5 / elapsed.Minutes()
+}
+
+// rawWPM is calculated from every character before the cursor, mistakes included
+func (s *Session) rawWPM(now time.Time) float64 {
+	elapsed := s.Elapsed(now)
+	if elapsed == 0 {
+		return 0
+	}
+	return float64(s.index) / 5 / elapsed.Minutes()
+}
+
+func (s *Session) Accuracy() float64 {
+	return float64(s.correct) / float64(s.correct+s.mistakes) * 100
+}
+
+// Result returns the stats of a finished session
+func (s *Session) Result() db.Result {
+	timeTaken := s.Elapsed(s.endTime)
+	total := len(s.typed) - s.skipped
+	if s.cfg.Zen {
+		total = s.correct + s.mistakes
+	}
+	var times []float64
+	for _, t := range s.times {
+		times = append(times, t.Seconds())
+	}
+	return db.Result{
+		WPM:        s.WPM(s.endTime),
+		Accuracy:   s.Accuracy(),
+		Correct:    s.correct,
+		Total:      total,
+		Mistakes:   s.mistakes,
+		TimeTaken:  timeTaken.Seconds(),
+		Pauses:     s.pauses,
+		PausedTime: s.pausedTotal.Seconds(),
+		Times:      times,
+		FailReason: s.failReason,
+	}
+}
+
+// Info returns the text of the info line
+func (s *Session) Info(now time.Time) string {
+	if s.cfg.Zen {
+		return s.zenInfo(now)
+	}
+	if s.finished {
+		result := s.Result()
+		info := fmt.Sprintf("%03.0fwpm  %s  %d/%d/%d  %.2f%%", result.WPM, s.Elapsed(now).Round(time.Second), result.Correct, result.Total, result.Mistakes, result.Accuracy)
+		if s.failReason != "" {
+			info = "failed: " + s.failReason + "  " + info
+		}
+		if s.Ghost != nil {
+			info += s.ghostInfo()
+		}
+		if s.cfg.Memorize > 0 {
+			info += s.recalledInfo()
+		}
+		if s.cfg.Attribution != "" {
+			info += "  - " + s.cfg.Attribution
+		}
+		return info
+	}
+	if s.memorizing && !s.hideAt.IsZero() {
+		return fmt.Sprintf("memorize the text  %s left, press a key to start typing", s.hideAt.Sub(now).Round(time.Second))
+	}
+	if !s.started {
+		return fmt.Sprintf("000wpm  0s  0/%d/0  100%%", s.text.Len())
+	}
+	info := fmt.Sprintf("%03.0fwpm  %s  %d/%d/%d  %.2f%%", s.WPM(now), s.Elapsed(now).Round(time.Second), s.correct, s.text.Len(), s.mistakes, s.Accuracy())
+	// the speed of everything typed and how far the cursor is do not give mistakes away
+	if s.cfg.Blind || s.cfg.Memorize > 0 {
+		info = fmt.Sprintf("%03.0fwpm  %s  %d/%d", s.rawWPM(now), s.Elapsed(now).Round(time.Second), s.index, s.text.Len())
+	}
+	if s.paused {
+		info += "  paused, press esc to continue"
+	}
+	return info
+}
+
+// InfoStyle returns how the info line should currently be displayed
+func (s *Session) InfoStyle() render.InfoStyle {
+	switch {
+	case s.failReason != "":
+		return render.InfoFailed
+	case s.finished:
+		return render.InfoDone
+	case s.started && !s.paused:
+		return render.InfoRunning
+	default:
+		return render.InfoStart
+	}
+}
+
+// Reflow splits the text into lines of at most width columns.
+// The cursor keeps its position in the text.
+func (s *Session) Reflow(width int) {
+	s.cfg.LineLength = width
+	s.text.Reflow(width)
+	// a wider text can have fewer lines left, they are drawn by the redraw after reflowing
+	s.fillLines(&Update{})
+}
+
+// Redraw draws the whole session again, including what has been typed so far.
+// The text is hidden while paused.
+func (s *Session) Redraw(r render.Renderer, now time.Time) {
+	if s.paused {
+		r.Clear()
+		r.SetInfo(s.Info(now), s.InfoStyle())
+		return
+	}
+	switch {
+	case s.recalling():
+		r.DrawText(s.blank())
+		for i := range s.typed[:s.index] {
+			row, column := s.text.Position(i)
+			r.SetChar(row, column, s.typed[i], render.Typed)
+		}
+	case s.cfg.Memorize > 0 && s.finished:
+		text, styles := s.recalledText()
+		r.DrawText(text)
+		for i, style := range styles {
+			row, column := text.Position(i)
+			r.SetChar(row, column, text.At(i), style)
+		}
+		r.SetInfo(s.Info(now), s.InfoStyle())
+		r.MoveCaret(text.Position(text.Len()))
+		return
+	default:
+		r.DrawText(s.text)
+		for i := range s.typed[:s.index] {
+			row, column := s.text.Position(i)
+			style := s.styleAt(i)
+			r.SetChar(row, column, s.revealed(i, style), s.shown(style))
+		}
+	}
+	if s.Ghost != nil && s.started {
+		row, column := s.text.Position(s.ghostIndex)
+		r.SetChar(row, column, s.text.At(s.ghostIndex), render.Ghost)
+	}
+	r.SetInfo(s.Info(now), s.InfoStyle())
+	r.MoveCaret(s.Position())
+}
+
+// Draw draws the changes of an update and moves the caret to the cursor
+func (s *Session) Draw(r render.Renderer, update Update) {
+	ghostHidden := false
+	for _, c := range update.Changes {
+		r.SetChar(c.Row, c.Column, c.Char, c.Style)
+		ghostHidden = ghostHidden || c.Index == s.ghostIndex && c.Style != render.Ghost
+	}
+	// typing over the ghost does not hide it
+	if s.Ghost != nil && s.started && ghostHidden {
+		row, column := s.text.Position(s.ghostIndex)
+		r.SetChar(row, column, s.text.At(s.ghostIndex), render.Ghost)
+	}
+	for _, line := range update.NewLines {
+		r.AppendLine([]rune(line))
+	}
+	r.MoveCaret(s.Position())
+}
@@ -0,0 +1,217 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+// keys turns s into key presses, \b is backspace and \x17 deletes a word like ctrl-backspace
+func keys(s string) []Key {
+	var ks []Key
+	for _, r := range s {
+		switch r {
+		case '\b':
+			ks = append(ks, Key{Type: KeyBackspace})
+		case '\x17':
+			ks = append(ks, Key{Type: KeyDeleteWord})
+		default:
+			ks = append(ks, Key{Type: KeyRune, Rune: r})
+		}
+	}
+	return ks
+}
+
+// press handles every key a tenth of a second apart after start and returns the time of the last one
+func press(s *Session, ks []Key, start time.Time) time.Time {
+	now := start
+	for _, k := range ks {
+		now = now.Add(100 * time.Millisecond)
+		s.Handle(k, now)
+	}
+	return now
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		lines []string
+		keys  string
+		// nextLine is added to the text in timed mode
+		nextLine string
+		// the text is reflowed to width before the after keys are pressed
+		width int
+		after string
+
+		index     int
+		correct   int
+		mistakes  int
+		row       int
+		column    int
+		finished  bool
+		lineCount int
+	}{
+		{
+			name:  "typing to the next line",
+			lines: []string{"hello ", "world"},
+			keys:  "hello w",
+			index: 7, correct: 7, row: 1, column: 1, lineCount: 2,
+		},
+		{
+			name:  "backspace across a line wrap",
+			lines: []string{"hello ", "world"},
+			keys:  "hello w\b\b",
+			index: 5, correct: 5, row: 0, column: 5, lineCount: 2,
+		},
+		{
+			name:  "backspace a mistake",
+			lines: []string{"hello ", "world"},
+			keys:  "hx\bel",
+			index: 3, correct: 3, mistakes: 1, row: 0, column: 3, lineCount: 2,
+		},
+		{
+			name:  "delete word stops at the word before",
+			lines: []string{"hello ", "world"},
+			keys:  "hello wo\x17",
+			index: 6, correct: 6, row: 1, column: 0, lineCount: 2,
+		},
+		{
+			name:  "delete word across a line wrap",
+			lines: []string{"hello ", "world"},
+			keys:  "hello \x17",
+			index: 0, row: 0, column: 0, lineCount: 2,
+		},
+		{
+			name:  "correct only waits for the right key",
+			cfg:   Config{CorrectOnly: true},
+			lines: []string{"ab"},
+			keys:  "xxab",
+			index: 2, correct: 1, mistakes: 2, row: 0, column: 2, finished: true, lineCount: 1,
+		},
+		{
+			name:  "correct only ignores backspace",
+			cfg:   Config{CorrectOnly: true},
+			lines: []string{"abc"},
+			keys:  "ab\b\x17",
+			index: 2, correct: 2, row: 0, column: 2, lineCount: 1,
+		},
+		{
+			name:  "no backspace",
+			cfg:   Config{NoBackspace: true},
+			lines: []string{"abc"},
+			keys:  "x\b\x17",
+			index: 1, mistakes: 1, row: 0, column: 1, lineCount: 1,
+		},
+		{
+			name:  "finished at the end of the text",
+			lines: []string{"ab ", "cd"},
+			keys:  "ab cd",
+			index: 5, correct: 5, row: 1, column: 2, finished: true, lineCount: 2,
+		},
+		{
+			name:  "finished with mistakes",
+			lines: []string{"ab"},
+			keys:  "xy",
+			index: 2, mistakes: 2, row: 0, column: 2, finished: true, lineCount: 1,
+		},
+		{
+			name:  "enter is ignored outside of code mode",
+			lines: []string{"ab"},
+			keys:  "a\n",
+			index: 1, correct: 1, row: 0, column: 1, lineCount: 1,
+		},
+		{
+			name:  "zen mode moves to a new line after enter",
+			cfg:   Config{Zen: true, LineLength: 10},
+			lines: []string{""},
+			keys:  "ab\n",
+			index: 3, correct: 3, row: 1, column: 0, lineCount: 2,
+		},
+		{
+			name:     "timed mode adds a line once the last one is reached",
+			cfg:      Config{TimedMode: time.Minute, LineLength: 10},
+			lines:    []string{"ab ", "cd "},
+			nextLine: "ef ",
+			keys:     "ab ",
+			index:    3, correct: 3, row: 1, column: 0, lineCount: 3,
+		},
+		{
+			name:     "timed mode does not add lines before the last one",
+			cfg:      Config{TimedMode: time.Minute, LineLength: 10},
+			lines:    []string{"ab ", "cd ", "ef "},
+			nextLine: "gh ",
+			keys:     "ab ",
+			index:    3, correct: 3, row: 1, column: 0, lineCount: 3,
+		},
+		{
+			name:     "timed mode adds lines when a resize leaves the cursor on the last line",
+			cfg:      Config{TimedMode: time.Minute, LineLength: 6},
+			lines:    []string{"ab cd ", "ef gh ", "ij kl "},
+			nextLine: "mn ",
+			keys:     "ab ",
+			width:    80,
+			after:    "cd ef gh ij kl ",
+			index:    18, correct: 18, row: 1, column: 0, lineCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.cfg, tt.lines)
+			if tt.nextLine != "" {
+				s.NextLine = func(int) string { return tt.nextLine }
+			}
+			now := press(s, keys(tt.keys), time.Unix(0, 0))
+			if tt.width > 0 {
+				s.Reflow(tt.width)
+				press(s, keys(tt.after), now)
+			}
+
+			if s.Index() != tt.index {
+				t.Errorf("index = %d, want %d", s.Index(), tt.index)
+			}
+			if s.Correct() != tt.correct {
+				t.Errorf("correct = %d, want %d", s.Correct(), tt.correct)
+			}
+			if s.Mistakes() != tt.mistakes {
+				t.Errorf("mistakes = %d, want %d", s.Mistakes(), tt.mistakes)
+			}
+			if row, column := s.Position(); row != tt.row || column != tt.column {
+				t.Errorf("position = %d,%d, want %d,%d", row, column, tt.row, tt.column)
+			}
+			if s.Finished() != tt.finished {
+				t.Errorf("finished = %v, want %v", s.Finished(), tt.finished)
+			}
+			if s.Text().Lines() != tt.lineCount {
+				t.Errorf("lines = %d, want %d", s.Text().Lines(), tt.lineCount)
+			}
+		})
+	}
+}
+
+func TestTimeUp(t *testing.T) {
+	s := New(Config{TimedMode: time.Second}, []string{"abc"})
+	start := time.Unix(0, 0)
+	s.Handle(Key{Type: KeyRune, Rune: 'a'}, start)
+	if s.Tick(start.Add(999 * time.Millisecond)).Finished {
+		t.Fatal("finished before the time is up")
+	}
+	if !s.Tick(start.Add(time.Second)).Finished {
+		t.Fatal("not finished after the time is up")
+	}
+	if got := s.Result().TimeTaken; got != 1 {
+		t.Errorf("time taken = %v, want 1", got)
+	}
+}
+
+func TestBlindInfo(t *testing.T) {
+	s := New(Config{Blind: true}, []string{"abc"})
+	now := time.Unix(0, 0)
+	s.Handle(Key{Type: KeyRune, Rune: 'x'}, now)
+	if got, want := s.Info(now), "000wpm  0s  1/3"; got != want {
+		t.Errorf("info = %q, want %q", got, want)
+	}
+	s.Handle(Key{Type: KeyRune, Rune: 'b'}, now.Add(6*time.Second))
+	if got, want := s.Info(now.Add(6*time.Second)), "004wpm  6s  2/3"; got != want {
+		t.Errorf("info = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fr3dr/termtyper/render"
+)
+
+// in zen mode there is no text to copy, everything typed becomes the text
+// and is always correct, characters removed again are counted as corrections
+
+func (s *Session) typeFree(char rune, now time.Time, update *Update) {
+	if !s.started {
+		s.startTime = now
+		s.started = true
+	}
+
+	s.text.Append(char)
+	s.typed = append(s.typed, char)
+	s.correct++
+	s.index++
+	s.rewrap(update)
+	if !update.Redraw {
+		s.change(update, s.index-1, render.Typed)
+	}
+}
+
+// untypeFree removes the last character typed in zen mode
+func (s *Session) untypeFree(update *Update) {
+	s.index--
+	s.correct--
+	s.mistakes++
+	s.typed = s.typed[:s.index]
+	s.text.Truncate(s.index)
+	s.text.Reflow(s.cfg.LineLength)
+	update.Redraw = true
+}
+
+// rewrap wraps the text again after it changed at the end,
+// everything is redrawn if a word moved to another line
+func (s *Session) rewrap(update *Update) {
+	lines, lastStart := s.text.Lines(), s.text.LineStart(s.text.Lines()-1)
+	s.text.Reflow(s.cfg.LineLength)
+	if s.text.Lines() != lines || s.text.LineStart(s.text.Lines()-1) != lastStart {
+		update.Redraw = true
+	}
+}
+
+func (s *Session) zenInfo(now time.Time) string {
+	info := fmt.Sprintf("%03.0fwpm  %s  %d chars  %d corrections", s.WPM(now), s.Elapsed(now).Round(time.Second), s.correct, s.mistakes)
+	switch {
+	case !s.started:
+		return "000wpm  0s  0 chars  0 corrections  ctrl-d to finish"
+	case s.paused:
+		info += "  paused, press esc to continue"
+	case !s.finished:
+		info += "  ctrl-d to finish"
+	}
+	return info
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/quotes"
+)
+
+// showStats prints the char stats and averages of all results,
+// failed tests are only included in the averages with includeFailed
+func showStats(stats *db.DB, includeFailed bool) error {
+	// get stats from database
+	results, charStats, err := stats.GetAll()
+	if err != nil {
+		return err
+	}
+
+	// print char stats
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "char \tcorrect \tincorrect \taccuracy")
+	fmt.Fprintln(w, "---- \t------- \t--------- \t--------")
+	for _, v := range charStats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f%%\n", charLabel(v.Char), v.Correct, v.Incorrect, v.Accuracy)
+	}
+	w.Flush()
+
+	// print general stats
+	var ammount float64
+	var sumWPM float64
+	var sumAccuracy float64
+	var sumMistakes float64
+	var totalTime time.Duration
+	var pausedTime time.Duration
+	var zenAmmount float64
+	var zenSumWPM float64
+	var failed int
+	var memoryAmmount float64
+	var memorySumAccuracy float64
+	for _, v := range results {
+		totalTime += time.Duration(v.TimeTaken) * time.Second
+		pausedTime += time.Duration(v.PausedTime) * time.Second
+		if v.FailReason != "" {
+			failed++
+			if !includeFailed {
+				continue
+			}
+		}
+		// zen results have nothing to be accurate to and are kept apart
+		if v.Mode == "zen" {
+			zenAmmount++
+			zenSumWPM += v.WPM
+			continue
+		}
+		if v.Mode == "memory" {
+			memoryAmmount++
+			memorySumAccuracy += v.Accuracy
+			continue
+		}
+		ammount++
+		sumWPM += v.WPM
+		sumAccuracy += v.Accuracy
+		sumMistakes += float64(v.Mistakes)
+	}
+	fmt.Printf("Average WPM: %.2f\n", sumWPM/ammount)
+	fmt.Printf("Average Accuracy: %.2f%%\n", sumAccuracy/ammount)
+	fmt.Printf("Average Mistakes: %.2f\n", sumMistakes/ammount)
+	fmt.Printf("Time spent typing: %v\n", totalTime.Round(time.Millisecond))
+	fmt.Printf("Time spent paused: %v\n", pausedTime.Round(time.Millisecond))
+	if zenAmmount > 0 {
+		fmt.Printf("Zen sessions: %.0f, average WPM: %.2f\n", zenAmmount, zenSumWPM/zenAmmount)
+	}
+	if memoryAmmount > 0 {
+		fmt.Printf("Memory tests: %.0f, average accuracy: %.2f%%\n", memoryAmmount, memorySumAccuracy/memoryAmmount)
+	}
+	if failed > 0 && !includeFailed {
+		fmt.Printf("Failed tests: %d, not included in the averages\n", failed)
+	}
+
+	// print best results per quote
+	quoteStats, err := stats.GetQuoteStats()
+	if err != nil {
+		return err
+	}
+	if len(quoteStats) > 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "quote 	runs 	best time 	best wpm 	source")
+		fmt.Fprintln(w, "----- 	---- 	--------- 	-------- 	------")
+		for _, v := range quoteStats {
+			q, _ := quotes.Get(v.QuoteID)
+			bestTime := time.Duration(v.BestTime * float64(time.Second))
+			fmt.Fprintf(w, "%d\t%d\t%v\t%.2f\t%s\n", v.QuoteID, v.Runs, bestTime.Round(time.Millisecond), v.BestWPM, q.Source)
+		}
+		w.Flush()
+	}
+
+	// print the slowest and most error-prone n-grams
+	for n, name := range []string{"bigram", "trigram"} {
+		nGramStats, err := stats.GetNGramStats(n + 2)
+		if err != nil {
+			return err
+		}
+		showNGrams(nGramStats, name)
+	}
+
+	// print how far every book has been typed
+	progress, err := stats.GetAllProgress()
+	if err != nil {
+		return err
+	}
+	if len(progress) > 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "book 	progress 	wpm 	time")
+		fmt.Fprintln(w, "---- 	-------- 	--- 	----")
+		for _, v := range progress {
+			totalTime := time.Duration(v.Time * float64(time.Second))
+			fmt.Fprintf(w, "%s\t%.1f%%\t%.2f\t%v\n", v.File, float64(v.Position)/float64(v.Length)*100, float64(v.Correct)/5/totalTime.Minutes(), totalTime.Round(time.Second))
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+// nGramRows is how many n-grams are listed
+const nGramRows = 10
+
+// showNGrams prints the slowest and the most error-prone n-grams that have been typed often enough
+func showNGrams(nGramStats []*db.NGramStat, name string) {
+	var typed []*db.NGramStat
+	for _, v := range nGramStats {
+		// the latency is only known for n-grams typed correctly at least once
+		if v.Count > 0 && v.Count+v.Errors >= minSamples {
+			typed = append(typed, v)
+		}
+	}
+
+	list := func(title string, typed []*db.NGramStat, less func(a, b *db.NGramStat) bool) {
+		if len(typed) == 0 {
+			return
+		}
+		sort.SliceStable(typed, func(i, j int) bool {
+			return less(typed[i], typed[j])
+		})
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		fmt.Fprintf(w, "%s \tlatency \terrors \ttyped\n", title)
+		fmt.Fprintf(w, "%s \t------- \t------ \t-----\n", strings.Repeat("-", len(title)))
+		for _, v := range typed[:min(nGramRows, len(typed))] {
+			latency := time.Duration(v.Latency() * float64(time.Second))
+			fmt.Fprintf(w, "%q\t%v\t%.2f%%\t%d\n", v.NGram, latency.Round(time.Millisecond), v.ErrorRate(), v.Count+v.Errors)
+		}
+		w.Flush()
+	}
+	list("slowest "+name+"s", typed, func(a, b *db.NGramStat) bool {
+		return a.Latency() > b.Latency()
+	})
+	var mistyped []*db.NGramStat
+	for _, v := range typed {
+		if v.Errors > 0 {
+			mistyped = append(mistyped, v)
+		}
+	}
+	list("most mistyped "+name+"s", mistyped, func(a, b *db.NGramStat) bool {
+		return a.ErrorRate() > b.ErrorRate()
+	})
+}
+
+// charLabel returns a printable name for a character in the stats table
+func charLabel(char rune) string {
+	switch {
+	case char == ' ':
+		return "space"
+	case char == '\n':
+		return "enter"
+	case char == '\t':
+		return "tab"
+	case !unicode.IsGraphic(char):
+		return fmt.Sprintf("%U", char)
+	default:
+		return string(char)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/fr3dr/termtyper/db"
+	"github.com/fr3dr/termtyper/words"
+)
+
+// symbolPool is how many tokens symbols mode generates to pick from
+const symbolPool = 500
+
+// symbolsSource generates tokens of digits and symbols,
+// the ones containing the least accurate characters of the set are picked more often
+func symbolsSource(stats *db.DB, set string, strength float64) (words.Source, string, error) {
+	gen, err := words.NewSymbols(set)
+	if err != nil {
+		return nil, "", err
+	}
+	charStats, err := stats.GetCharStats()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// letters around the symbols are not practiced
+	var practiced []*db.CharStat
+	for _, v := range charStats {
+		if strings.ContainsRune(set, v.Char) {
+			practiced = append(practiced, v)
+		}
+	}
+	pool := make([]string, symbolPool)
+	for i := range pool {
+		pool[i] = gen.Next()
+	}
+	focus := weakChars(practiced, pool)
+	if len(focus) == 0 {
+		return gen, "", nil
+	}
+	return words.NewWeighted(pool, focus, strength), focusInfo(focus), nil
+}
@@ -0,0 +1,146 @@
+package target
+
+import "sort"
+
+// Text is the text that has to be typed.
+// The runes of all lines are stored once in a single buffer and
+// the start of every line is tracked so positions can be looked up directly.
+type Text struct {
+	runes      []rune
+	lineStarts []int
+}
+
+func New(lines []string) *Text {
+	t := &Text{}
+	for _, line := range lines {
+		t.AppendLine(line)
+	}
+	return t
+}
+
+// AppendLine adds a line to the end of the text
+func (t *Text) AppendLine(line string) {
+	t.lineStarts = append(t.lineStarts, len(t.runes))
+	t.runes = append(t.runes, []rune(line)...)
+}
+
+// Append adds a rune to the end of the last line
+func (t *Text) Append(r rune) {
+	t.runes = append(t.runes, r)
+}
+
+// Truncate removes everything after the first n runes
+func (t *Text) Truncate(n int) {
+	t.runes = t.runes[:n]
+	for len(t.lineStarts) > 1 && t.lineStarts[len(t.lineStarts)-1] >= n {
+		t.lineStarts = t.lineStarts[:len(t.lineStarts)-1]
+	}
+}
+
+// Len returns the number of runes in the text
+func (t *Text) Len() int {
+	return len(t.runes)
+}
+
+// At returns the rune at index
+func (t *Text) At(index int) rune {
+	return t.runes[index]
+}
+
+// Runes returns the runes from start up to end
+func (t *Text) Runes(start, end int) []rune {
+	return t.runes[start:end]
+}
+
+// Lines returns the number of lines
+func (t *Text) Lines() int {
+	return len(t.lineStarts)
+}
+
+// LineStart returns the index of the first rune of a line
+func (t *Text) LineStart(row int) int {
+	return t.lineStarts[row]
+}
+
+// LineLen returns the number of runes in a line
+func (t *Text) LineLen(row int) int {
+	if row+1 < len(t.lineStarts) {
+		return t.lineStarts[row+1] - t.lineStarts[row]
+	}
+	return len(t.runes) - t.lineStarts[row]
+}
+
+// Line returns the runes of a line
+func (t *Text) Line(row int) []rune {
+	start := t.lineStarts[row]
+	return t.runes[start : start+t.LineLen(row)]
+}
+
+// Position returns the row and display column of index.
+// The end of the text is positioned after the last rune of the last line,
+// or at the start of an empty last line if the text ends with a newline.
+func (t *Text) Position(index int) (int, int) {
+	row := sort.Search(len(t.lineStarts), func(i int) bool {
+		return t.lineStarts[i] > index
+	}) - 1
+	if row < 0 {
+		return 0, 0
+	}
+	column := 0
+	for _, r := range t.runes[t.lineStarts[row]:index] {
+		column += RuneWidth(r)
+	}
+	return row, column
+}
+
+// Reflow splits the text into new lines that are at most width columns wide.
+// Lines are broken after spaces and newlines, words wider than width get a line of their own.
+func (t *Text) Reflow(width int) {
+	t.lineStarts = []int{0}
+	lineWidth := 0
+	for i := 0; i < len(t.runes); {
+		// a word and the spaces following it
+		j := i
+		wordWidth := 0
+		for j < len(t.runes) && t.runes[j] != ' ' && t.runes[j] != '\n' {
+			wordWidth += RuneWidth(t.runes[j])
+			j++
+		}
+		for j < len(t.runes) && t.runes[j] == ' ' {
+			wordWidth++
+			j++
+		}
+		newline := j < len(t.runes) && t.runes[j] == '\n'
+		if newline {
+			wordWidth++
+			j++
+		}
+
+		if lineWidth > 0 && lineWidth+wordWidth > width {
+			t.lineStarts = append(t.lineStarts, i)
+			lineWidth = 0
+		}
+		lineWidth += wordWidth
+		i = j
+
+		// a newline at the end starts an empty last line for the cursor
+		if newline {
+			t.lineStarts = append(t.lineStarts, i)
+			lineWidth = 0
+		}
+	}
+}
+
+// Strings returns every line as a string
+func (t *Text) Strings() []string {
+	lines := make([]string, t.Lines())
+	for row := range lines {
+		lines[row] = string(t.Line(row))
+	}
+	return lines
+}
+
+// String returns the whole text without line breaks
+func (t *Text) String() string {
+	return string(t.runes)
+}
@@ -0,0 +1,99 @@
+package target
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"fits", "ab cd", 10, []string{"ab cd"}},
+		{"wrapped after the space", "ab cd ef", 5, []string{"ab ", "cd ef"}},
+		{"trailing spaces count", "abc def", 4, []string{"abc ", "def"}},
+		{"space at the end of a full line", "ab cd", 3, []string{"ab ", "cd"}},
+		{"several spaces stay with the word", "ab   cd", 4, []string{"ab   ", "cd"}},
+		{"word wider than the width", "a abcdefgh b", 4, []string{"a ", "abcdefgh ", "b"}},
+		{"wide runes", "日本 ab", 5, []string{"日本 ", "ab"}},
+		{"wide runes fit", "日本 ab", 7, []string{"日本 ab"}},
+		{"newlines", "ab\ncd\n\nef", 10, []string{"ab\n", "cd\n", "\n", "ef"}},
+		{"newline after a wrap", "ab cd\nef", 3, []string{"ab ", "cd\n", "ef"}},
+		{"newline at the end", "ab\n", 10, []string{"ab\n", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := New([]string{tt.text})
+			text.Reflow(tt.width)
+			if got := text.Strings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPosition(t *testing.T) {
+	text := New([]string{"日本 ", "ab"})
+	tests := []struct {
+		index  int
+		row    int
+		column int
+	}{
+		{0, 0, 0},
+		{1, 0, 2},
+		{2, 0, 4},
+		{3, 1, 0},
+		{4, 1, 1},
+		// the end of the text
+		{5, 1, 2},
+	}
+	for _, tt := range tests {
+		if row, column := text.Position(tt.index); row != tt.row || column != tt.column {
+			t.Errorf("Position(%d) = %d,%d, want %d,%d", tt.index, row, column, tt.row, tt.column)
+		}
+	}
+}
+
+func TestPositionAfterNewline(t *testing.T) {
+	text := New([]string{"ab\n"})
+	text.Reflow(10)
+	if row, column := text.Position(3); row != 1 || column != 0 {
+		t.Errorf("Position(3) = %d,%d, want 1,0", row, column)
+	}
+}
+
+func TestLineLen(t *testing.T) {
+	text := New([]string{"日本 ", "", "abc"})
+	for row, want := range []int{3, 0, 3} {
+		if got := text.LineLen(row); got != want {
+			t.Errorf("LineLen(%d) = %d, want %d", row, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"inside a line", 4, []string{"ab ", "c"}},
+		{"at a line start", 3, []string{"ab "}},
+		{"everything", 0, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := New([]string{"ab ", "cd ", "ef"})
+			text.Truncate(tt.n)
+			if got := text.Strings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+			if text.Len() != tt.n {
+				t.Errorf("len = %d, want %d", text.Len(), tt.n)
+			}
+		})
+	}
+}
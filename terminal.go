@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// xterm cursor shape escape codes
+var (
+	defaultCursor   = "\033[0 q"
+	blockCursor     = "\033[1 q"
+	underlineCursor = "\033[3 q"
+	barCursor       = "\033[5 q"
+)
+
+// cursorCode returns the escape code of a cursor shape
+func cursorCode(shape string) string {
+	switch shape {
+	case "block":
+		return blockCursor
+	case "bar":
+		return barCursor
+	case "underline":
+		return underlineCursor
+	default:
+		return defaultCursor
+	}
+}
+
+// terminal keeps track of the changes made to the terminal so they can be undone
+// on every way the program exits or gets suspended.
+// The mode of the terminal can be changed from any goroutine, but only the main goroutine
+// writes to stdout: fatalf and suspend reset the cursor shape and are only called from there.
+type terminal struct {
+	mu sync.Mutex
+	// in is where keys are read from, fd is the terminal put into raw mode
+	in       *os.File
+	fd       int
+	oldState *term.State
+}
+
+func newTerminal(in *os.File, fd int) *terminal {
+	return &terminal{in: in, fd: fd}
+}
+
+// makeRaw puts the terminal into raw mode
+func (t *terminal) makeRaw() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	oldState, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return err
+	}
+	if t.oldState == nil {
+		t.oldState = oldState
+	}
+	return nil
+}
+
+// restore puts the terminal back into the state it was in before makeRaw
+func (t *terminal) restore() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.oldState != nil {
+		term.Restore(t.fd, t.oldState)
+	}
+}
+
+// resetCursor sets the default cursor shape again
+func (t *terminal) resetCursor() {
+	fmt.Printf("%s", defaultCursor)
+}
+
+// cleanup restores the terminal and repanics if there is a panic.
+// It has to be deferred directly for recover to work.
+func (t *terminal) cleanup() {
+	r := recover()
+	t.restore()
+	if r != nil {
+		panic(r)
+	}
+}
+
+// fatalf restores the terminal before exiting with an error
+func (t *terminal) fatalf(format string, v ...any) {
+	t.restore()
+	t.resetCursor()
+	log.Fatalf(format, v...)
+}
+
+// suspend restores the terminal and stops the process like ctrl-z normally would.
+// It returns once the process is continued, with the terminal back in raw mode.
+// SIGTSTP is caught to get here, so the process is stopped with SIGSTOP.
+func (t *terminal) suspend() {
+	t.restore()
+	t.resetCursor()
+	syscall.Kill(0, syscall.SIGSTOP)
+	if err := t.makeRaw(); err != nil {
+		t.fatalf("Failed to restore raw mode: %v", err)
+	}
+}
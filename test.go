@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fr3dr/termtyper/config"
+	"github.com/fr3dr/termtyper/lessons"
+	"github.com/fr3dr/termtyper/quotes"
+	"github.com/fr3dr/termtyper/words"
+)
+
+// sources are what the text of tests is generated from
+type sources struct {
+	words words.Source
+	// focus describes what adaptive, drill or symbols mode practices
+	focus  string
+	code   []string
+	book   *book
+	lesson *lessons.Lesson
+	// rows is how many lines of code or a book fit on the screen
+	rows int
+}
+
+// bookRows is the most lines of a book typed in one test
+const bookRows = 5
+
+// test is the text of a single typing test and where it came from
+type test struct {
+	lines     []string
+	mode      string
+	timedMode time.Duration
+	code      bool
+	zen       bool
+	// memorize is how long the text is shown in memory mode
+	memorize time.Duration
+	// words keeps generating lines in timed mode
+	words words.Source
+	// set for quotes
+	quoteID     int
+	attribution string
+	// bookEnd is the position in the book after the test
+	bookEnd int
+	lesson  *lessons.Lesson
+}
+
+// textKey identifies the text of a test to find previous runs of it,
+// timed and zen tests have no fixed text
+func (t test) textKey() string {
+	if t.zen || t.timedMode > 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(t.lines, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// newTest generates the text of a new test for the configured mode
+func newTest(cfg config.Config, src sources, lineLength int) (test, error) {
+	// sentences start over with every test
+	gen := words.Modify(src.words, words.Modifiers{
+		Punctuation: cfg.Punctuation,
+		Capitals:    cfg.Capitals,
+		Numbers:     cfg.Numbers,
+	})
+	switch {
+	case cfg.Zen:
+		// the text is whatever gets typed, timed mode just limits how long
+		return test{
+			lines:     []string{""},
+			mode:      "zen",
+			timedMode: time.Duration(cfg.TimedMode) * time.Second,
+			zen:       true,
+		}, nil
+	case src.book != nil:
+		page, end := src.book.page(lineLength, min(src.rows, bookRows))
+		mode := "book"
+		if src.book.path == "" {
+			mode = "stdin"
+		}
+		return test{
+			lines:       []string{page},
+			mode:        mode,
+			attribution: fmt.Sprintf("%s %.1f%%", src.book.name(), src.book.percent(end)),
+			bookEnd:     end,
+		}, nil
+	case cfg.CodeFile != "":
+		return test{
+			lines: codeBlock(src.code, src.rows),
+			mode:  "code",
+			code:  true,
+		}, nil
+	case cfg.Quote != "":
+		q, err := quotes.Pick(cfg.Quote)
+		if err != nil {
+			return test{}, err
+		}
+		return test{
+			lines:       []string{q.Text},
+			mode:        "quote",
+			quoteID:     q.ID,
+			attribution: q.Source,
+		}, nil
+	case src.lesson != nil:
+		// lessons bring their own generators
+		return test{
+			lines:       words.Lines(src.words, lineLength, cfg.WordCount),
+			mode:        "lesson",
+			attribution: lessonInfo(src.lesson),
+			lesson:      src.lesson,
+		}, nil
+	case cfg.Memory > 0:
+		return test{
+			lines:       words.Lines(gen, lineLength, cfg.WordCount),
+			mode:        "memory",
+			memorize:    time.Duration(cfg.Memory) * time.Second,
+			attribution: src.focus,
+		}, nil
+	case cfg.Intervals > 0:
+		sprint := cfg.TimedMode
+		if sprint <= 0 {
+			sprint = defaultSprint
+		}
+		return test{
+			lines:       []string{words.Line(gen, lineLength), words.Line(gen, lineLength), words.Line(gen, lineLength)},
+			mode:        "interval",
+			timedMode:   time.Duration(sprint) * time.Second,
+			words:       gen,
+			attribution: src.focus,
+		}, nil
+	case cfg.TimedMode > 0:
+		// timed mode starts with 3 lines and generates more while typing
+		return test{
+			lines:       []string{words.Line(gen, lineLength), words.Line(gen, lineLength), words.Line(gen, lineLength)},
+			mode:        "time",
+			timedMode:   time.Duration(cfg.TimedMode) * time.Second,
+			words:       gen,
+			attribution: src.focus,
+		}, nil
+	case cfg.Symbols:
+		return test{
+			lines:       words.Lines(gen, lineLength, cfg.WordCount),
+			mode:        "symbols",
+			attribution: src.focus,
+		}, nil
+	default:
+		return test{
+			lines:       words.Lines(gen, lineLength, cfg.WordCount),
+			mode:        "words",
+			attribution: src.focus,
+		}, nil
+	}
+}
@@ -0,0 +1,99 @@
+package words
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modifiers are the changes made to generated words
+type Modifiers struct {
+	Punctuation bool
+	Capitals    bool
+	Numbers     bool
+}
+
+// Modified applies modifiers to the words of another source.
+// Sentences are tracked so the word after a period starts with a capital.
+type Modified struct {
+	src  Source
+	mods Modifiers
+	// sentenceStart is set when the next word starts a new sentence
+	sentenceStart bool
+}
+
+// Modify returns src with the modifiers applied, src is returned as is without any modifiers
+func Modify(src Source, mods Modifiers) Source {
+	if mods == (Modifiers{}) {
+		return src
+	}
+	return &Modified{src: src, mods: mods, sentenceStart: true}
+}
+
+func (m *Modified) Next() string {
+	word := m.src.Next()
+	if m.mods.Numbers && rand.IntN(10) == 0 {
+		word = number()
+	}
+
+	start := m.sentenceStart
+	m.sentenceStart = false
+	if m.mods.Capitals && (start || rand.IntN(20) == 0) {
+		word = capitalise(word)
+	}
+	if m.mods.Punctuation {
+		word = m.punctuate(word)
+	}
+	return word
+}
+
+// punctuate wraps a word in quotes or parentheses and ends it with a comma or a sentence end,
+// the frequencies are roughly those of english prose
+func (m *Modified) punctuate(word string) string {
+	switch n := rand.IntN(100); {
+	case n < 4:
+		word = `"` + word + `"`
+	case n < 6:
+		word = "(" + word + ")"
+	}
+
+	switch n := rand.IntN(100); {
+	case n < 10:
+		word += ","
+	case n < 18:
+		word += "."
+		m.sentenceStart = true
+	case n < 20:
+		word += "?"
+		m.sentenceStart = true
+	case n < 21:
+		word += "!"
+		m.sentenceStart = true
+	case n < 22:
+		word += ";"
+	case n < 23:
+		word += ":"
+	}
+	return word
+}
+
+// number returns a random number that is mostly short like in prose
+func number() string {
+	switch n := rand.IntN(10); {
+	case n < 5:
+		return strconv.Itoa(rand.IntN(100))
+	case n < 8:
+		return strconv.Itoa(rand.IntN(1000))
+	default:
+		return strconv.Itoa(1900 + rand.IntN(150))
+	}
+}
+
+func capitalise(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToUpper(r)) + word[size:]
+}
@@ -0,0 +1,89 @@
+package words
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"unicode"
+)
+
+// DefaultSymbolSet is the number row and the symbols of a us keyboard
+const DefaultSymbolSet = "0123456789!@#$%^&*()-_=+[]{}\\|;:'\",.<>/?`~"
+
+// pairs are the symbols that come in pairs, the closing symbol of each opening one
+var pairs = map[rune]rune{'(': ')', '[': ']', '{': '}', '<': '>', '"': '"', '\'': '\'', '`': '`'}
+
+// names are what symbols are put around when not numbers
+var names = []string{"a", "b", "i", "n", "x", "y", "id", "key", "val", "err"}
+
+// Symbols generates short tokens of digits and symbols like 3.14, #42, (x), a_b and 0x1F
+type Symbols struct {
+	digits  []rune
+	symbols []rune
+}
+
+// NewSymbols returns a generator using the digits and symbols of set, letters and spaces are ignored
+func NewSymbols(set string) (*Symbols, error) {
+	s := &Symbols{}
+	for _, r := range set {
+		switch {
+		case unicode.IsDigit(r):
+			s.digits = append(s.digits, r)
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			s.symbols = append(s.symbols, r)
+		}
+	}
+	if len(s.digits) == 0 && len(s.symbols) == 0 {
+		return nil, errors.New("symbol set has no digits or symbols")
+	}
+	return s, nil
+}
+
+func (s *Symbols) Next() string {
+	if len(s.symbols) == 0 || (len(s.digits) > 0 && rand.IntN(4) == 0) {
+		return s.number()
+	}
+
+	c := s.symbols[rand.IntN(len(s.symbols))]
+	if close, ok := pairs[c]; ok {
+		return string(c) + s.operand() + string(close)
+	}
+	for open, close := range pairs {
+		if c == close {
+			return string(open) + s.operand() + string(close)
+		}
+	}
+	switch rand.IntN(3) {
+	case 0:
+		return string(c) + s.operand()
+	case 1:
+		return s.operand() + string(c) + s.operand()
+	default:
+		return s.operand() + string(c)
+	}
+}
+
+// operand is what a symbol is put next to, a number if there are digits or a short name
+func (s *Symbols) operand() string {
+	if len(s.digits) > 0 && rand.IntN(2) == 0 {
+		return s.number()
+	}
+	return names[rand.IntN(len(names))]
+}
+
+// number returns 1 to 3 digits of the set, sometimes written as hex if 0 is in the set
+func (s *Symbols) number() string {
+	var n []rune
+	for range 1 + rand.IntN(3) {
+		n = append(n, s.digits[rand.IntN(len(s.digits))])
+	}
+	if slices.Contains(s.digits, '0') && rand.IntN(8) == 0 {
+		for i := range n {
+			if rand.IntN(3) == 0 {
+				n[i] = rune('A' + rand.IntN(6))
+			}
+		}
+		return "0x" + string(n)
+	}
+	return string(n)
+}
@@ -0,0 +1,36 @@
+package words
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// Weighted picks words from a word list, words containing focus characters or n-grams are picked more often
+type Weighted struct {
+	words []string
+	// cumulative is the sum of the weights of all words up to and including each word
+	cumulative []float64
+}
+
+// NewWeighted weights every word with 1 plus strength times how often the focus strings appear in it,
+// a strength of 0 picks every word equally often
+func NewWeighted(words []string, focus []string, strength float64) *Weighted {
+	w := &Weighted{words: words, cumulative: make([]float64, len(words))}
+	sum := 0.0
+	for i, word := range words {
+		n := 0
+		for _, f := range focus {
+			n += strings.Count(word, f)
+		}
+		sum += 1 + strength*float64(n)
+		w.cumulative[i] = sum
+	}
+	return w
+}
+
+func (w *Weighted) Next() string {
+	x := rand.Float64() * w.cumulative[len(w.cumulative)-1]
+	i := sort.SearchFloat64s(w.cumulative, x)
+	return w.words[min(i, len(w.words)-1)]
+}
@@ -0,0 +1,55 @@
+package words
+
+import (
+	"math/rand/v2"
+
+	"github.com/fr3dr/termtyper/target"
+)
+
+// Source produces the words of a test one at a time
+type Source interface {
+	Next() string
+}
+
+// List picks random words from a word list
+type List struct {
+	words []string
+}
+
+func NewList(words []string) *List {
+	return &List{words: words}
+}
+
+func (l *List) Next() string {
+	return l.words[rand.IntN(len(l.words))]
+}
+
+// Lines generates count words split into lines of at most width columns
+func Lines(src Source, width int, count int) []string {
+	line := ""
+	var lines []string
+	for i := 1; ; i++ {
+		word := src.Next()
+		if line != "" && target.Width(line)+target.Width(word)+1 > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		line += word
+		if i >= count {
+			return append(lines, line)
+		}
+		line += " "
+	}
+}
+
+// Line generates a single line of at most width columns, used to keep timed mode going
+func Line(src Source, width int) string {
+	line := ""
+	for {
+		word := src.Next()
+		if line != "" && target.Width(line)+target.Width(word)+1 > width {
+			return line
+		}
+		line += word + " "
+	}
+}